@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/generic"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/common/spec"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-client-go/artifactory/services"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+// The Artifactory operations the folders and files commands depend on.
+// Keeping them behind an interface allows running the whole scan and delete flow against a fake backend in tests.
+type rmEmptyBackend interface {
+	// Searches for all the files under the path, and the folders as well if includeDirs is true.
+	// Returns a reader of the search results, which hold the properties of each item under the "props" key.
+	search(path string, includeDirs bool) (*content.ContentReader, error)
+	// Runs the AQL query and returns a reader of the ResultItems found.
+	execAql(aqlQuery string) (*content.ContentReader, error)
+	// Deletes a single file or folder.
+	deleteItem(item *clientrtutils.ResultItem) error
+	// Returns the keys of all the local repositories.
+	getLocalRepositories() ([]string, error)
+	// Returns the URL of the Artifactory server.
+	getArtifactoryUrl() string
+}
+
+// An rmEmptyBackend which runs the operations against an Artifactory server.
+type artifactoryBackend struct {
+	rtDetails      *config.ServerDetails
+	rtConf         clientrtutils.CommonConf
+	deleteItemFunc deleteItemFunc
+}
+
+func newArtifactoryBackend(rtDetails *config.ServerDetails) (*artifactoryBackend, error) {
+	authConfig, err := rtDetails.CreateArtAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+	rtConf, err := clientrtutils.NewCommonConfImpl(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	deleteFunc, err := newDeleteItemFunc(rtDetails)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactoryBackend{rtDetails: rtDetails, rtConf: rtConf, deleteItemFunc: deleteFunc}, nil
+}
+
+func (b *artifactoryBackend) search(path string, includeDirs bool) (*content.ContentReader, error) {
+	spec := spec.NewBuilder().Pattern(path).IncludeDirs(includeDirs).Recursive(true).BuildSpec()
+	cmd := generic.NewSearchCommand()
+	cmd.SetServerDetails(b.rtDetails).SetSpec(spec).SetRetries(3)
+	return cmd.Search()
+}
+
+func (b *artifactoryBackend) execAql(aqlQuery string) (*content.ContentReader, error) {
+	return clientrtutils.ExecAqlSaveToFile(aqlQuery, b.rtConf)
+}
+
+func (b *artifactoryBackend) deleteItem(item *clientrtutils.ResultItem) error {
+	return b.deleteItemFunc(item)
+}
+
+func (b *artifactoryBackend) getLocalRepositories() ([]string, error) {
+	serviceManager, err := utils.CreateServiceManager(b.rtDetails, 3, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	repositories, err := serviceManager.GetAllRepositoriesFiltered(services.RepositoriesFilterParams{RepoType: "local"})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, repository := range *repositories {
+		keys = append(keys, repository.Key)
+	}
+	return keys, nil
+}
+
+func (b *artifactoryBackend) getArtifactoryUrl() string {
+	return b.rtDetails.ArtifactoryUrl
+}
@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+// An rmEmptyBackend which holds the repositories as an in-memory tree of items.
+type fakeBackend struct {
+	t     *testing.T
+	mutex sync.Mutex
+	// The files and folders in Artifactory, with their full paths, starting with the repository.
+	items      []clientrtutils.ResultItem
+	localRepos []string
+	// The properties queried by the levels strategy, when folders are excluded by their properties.
+	excludeProps map[string][]string
+	// Paths of items that fail to be deleted.
+	failingPaths map[string]bool
+	deleted      []string
+}
+
+func newFakeBackend(t *testing.T, items []clientrtutils.ResultItem) *fakeBackend {
+	return &fakeBackend{t: t, items: items}
+}
+
+func (b *fakeBackend) search(searchPath string, includeDirs bool) (*content.ContentReader, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var results []searchResultItem
+	for _, item := range b.items {
+		if !isInFolder(item.Path, searchPath) || (item.Type == "folder" && !includeDirs) {
+			continue
+		}
+		result := searchResultItem{ResultItem: item}
+		result.Properties = nil
+		for _, property := range item.Properties {
+			if result.Props == nil {
+				result.Props = make(map[string][]string)
+			}
+			result.Props[property.Key] = append(result.Props[property.Key], property.Value)
+		}
+		results = append(results, result)
+	}
+	return writeFakeResults(results)
+}
+
+func (b *fakeBackend) execAql(aqlQuery string) (*content.ContentReader, error) {
+	b.mutex.Lock()
+	items := append([]clientrtutils.ResultItem(nil), b.items...)
+	b.mutex.Unlock()
+
+	// The manifest queries for the subtree of each folder before deleting it.
+	for _, folder := range items {
+		if folder.Type != "folder" || aqlQuery != buildSubtreeFoldersAQL(folder.Path) {
+			continue
+		}
+		var subtree []clientrtutils.ResultItem
+		for _, item := range items {
+			if item.Type == "folder" && (item.Path == folder.Path || isInFolder(item.Path, folder.Path)) {
+				parent, name := path.Split(item.Path)
+				repo, pathInRepo := splitRepoPath(path.Clean(parent))
+				subtree = append(subtree, clientrtutils.ResultItem{Repo: repo, Path: pathInRepo, Name: name, Type: item.Type, Properties: item.Properties})
+			}
+		}
+		return writeFakeResults(subtree)
+	}
+	// Otherwise, the query lists a folder for the levels strategy.
+	return newFakeListing(b.t, ".", items, levelsPageSize, b.excludeProps)(aqlQuery)
+}
+
+func (b *fakeBackend) deleteItem(item *clientrtutils.ResultItem) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.failingPaths[item.Path] {
+		return errors.New("forbidden")
+	}
+	var remaining []clientrtutils.ResultItem
+	found := false
+	for _, existing := range b.items {
+		if existing.Path == item.Path {
+			found = true
+		} else if !isInFolder(existing.Path, item.Path) {
+			remaining = append(remaining, existing)
+		}
+	}
+	if !found {
+		return errors.New("not found: " + item.Path)
+	}
+	b.items = remaining
+	b.deleted = append(b.deleted, item.Path)
+	return nil
+}
+
+func (b *fakeBackend) getLocalRepositories() ([]string, error) {
+	return b.localRepos, nil
+}
+
+func (b *fakeBackend) getArtifactoryUrl() string {
+	return "http://localhost:8081/artifactory/"
+}
+
+// Returns the paths of the items deleted, sorted.
+func (b *fakeBackend) getDeleted() []string {
+	deleted := append([]string(nil), b.deleted...)
+	sort.Strings(deleted)
+	return deleted
+}
+
+// Returns the paths of the items left in the tree.
+func (b *fakeBackend) getRemaining() []string {
+	var remaining []string
+	for _, item := range b.items {
+		remaining = append(remaining, item.Path)
+	}
+	sort.Strings(remaining)
+	return remaining
+}
+
+func writeFakeResults[T any](results []T) (*content.ContentReader, error) {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		writer.Write(result)
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+}
+
+func getTestRepoTree() []clientrtutils.ResultItem {
+	old, recent := "2020-01-01T00:00:00.000Z", time.Now().UTC().Format(time.RFC3339)
+	return []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder", Created: old, Modified: old},
+		{Path: "repo/a/lib.jar", Type: "file", Size: 10, Created: old, Modified: old},
+		{Path: "repo/a/b", Type: "folder", Created: old, Modified: old},
+		{Path: "repo/a/b/c", Type: "folder", Created: old, Modified: old, Properties: []clientrtutils.Property{{Key: "owner", Value: "ci"}}},
+		{Path: "repo/d", Type: "folder", Created: old, Modified: old},
+		// Excluded by its path.
+		{Path: "repo/keep", Type: "folder", Created: old, Modified: old},
+		{Path: "repo/keep/x", Type: "folder", Created: old, Modified: old},
+		// Excluded by its properties.
+		{Path: "repo/tagged", Type: "folder", Created: old, Modified: old, Properties: []clientrtutils.Property{{Key: "retain", Value: "true"}}},
+		// Too recent for the min age.
+		{Path: "repo/new", Type: "folder", Created: recent, Modified: recent},
+	}
+}
+
+func getTestFoldersConfiguration(t *testing.T) *foldersConfiguration {
+	excludePatterns, err := parseExcludePatterns("repo/keep/*")
+	assert.NoError(t, err)
+	return &foldersConfiguration{
+		paths:           []string{"repo/"},
+		quiet:           true,
+		format:          textFormat,
+		minAge:          time.Hour,
+		excludePatterns: excludePatterns,
+		excludeProps:    map[string][]string{"retain": {"true"}},
+		scanStrategy:    searchStrategy,
+		scanThreads:     defaultScanThreads,
+		threads:         2,
+	}
+}
+
+func TestDeleteEmptyFoldersFlow(t *testing.T) {
+	for _, strategy := range []string{searchStrategy, levelsStrategy} {
+		t.Run(strategy, func(t *testing.T) {
+			backend := newFakeBackend(t, getTestRepoTree())
+			conf := getTestFoldersConfiguration(t)
+			conf.scanStrategy = strategy
+			backend.excludeProps = conf.excludeProps
+
+			summary, err := deleteEmptyFolders(backend, conf, new(bytes.Buffer))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"repo/"}, summary.Paths)
+			assert.Equal(t, len(getTestRepoTree()), summary.Scanned)
+			assert.Equal(t, 2, summary.Found)
+			assert.Equal(t, 2, summary.Deleted)
+			assert.Zero(t, summary.Failed)
+			assert.Equal(t, []string{"repo/a/b", "repo/d"}, backend.getDeleted())
+			assert.Equal(t, []string{"repo/a", "repo/a/lib.jar", "repo/keep", "repo/keep/x", "repo/new", "repo/tagged"}, backend.getRemaining())
+		})
+	}
+}
+
+func TestDeleteEmptyFoldersDryRun(t *testing.T) {
+	backend := newFakeBackend(t, getTestRepoTree())
+	conf := getTestFoldersConfiguration(t)
+	conf.dryRun = true
+
+	output := new(bytes.Buffer)
+	summary, err := deleteEmptyFolders(backend, conf, output)
+	assert.NoError(t, err)
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, 2, summary.Found)
+	assert.Zero(t, summary.Deleted)
+	assert.Empty(t, backend.deleted)
+	assert.Len(t, backend.items, len(getTestRepoTree()))
+	assert.Equal(t, "repo/a/b\nrepo/d\n", output.String())
+}
+
+func TestDeleteEmptyFoldersPartialFailure(t *testing.T) {
+	backend := newFakeBackend(t, getTestRepoTree())
+	backend.failingPaths = map[string]bool{"repo/d": true}
+	conf := getTestFoldersConfiguration(t)
+
+	summary, err := deleteEmptyFolders(backend, conf, new(bytes.Buffer))
+	assert.EqualError(t, err, "failed deleting 1 of 2 empty folders")
+	assert.Equal(t, 1, summary.Deleted)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, []string{"repo/a/b"}, backend.getDeleted())
+	assert.Contains(t, backend.getRemaining(), "repo/d")
+}
+
+func TestDeleteEmptyFoldersManifest(t *testing.T) {
+	backend := newFakeBackend(t, getTestRepoTree())
+	conf := getTestFoldersConfiguration(t)
+	conf.manifestPath = filepath.Join(t.TempDir(), "manifest.json")
+
+	_, err := deleteEmptyFolders(backend, conf, new(bytes.Buffer))
+	assert.NoError(t, err)
+	m, err := loadManifest(conf.manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, backend.getArtifactoryUrl(), m.ArtifactoryUrl)
+	assert.Equal(t, []manifestFolder{
+		{Path: "repo/a/b"},
+		{Path: "repo/a/b/c", Properties: map[string][]string{"owner": {"ci"}}},
+		{Path: "repo/d"},
+	}, m.Folders)
+}
+
+func TestDeleteEmptyFoldersAllLocalRepos(t *testing.T) {
+	backend := newFakeBackend(t, []clientrtutils.ResultItem{
+		{Path: "libs-local/a", Type: "folder"},
+		{Path: "libs-local/b", Type: "folder"},
+		{Path: "libs-local/b/file.txt", Type: "file"},
+		{Path: "tools-local/c", Type: "folder"},
+	})
+	backend.localRepos = []string{"tools-local", "libs-local"}
+	conf := &foldersConfiguration{allLocalRepos: true, quiet: true, format: textFormat, scanStrategy: searchStrategy, threads: 1}
+
+	_, err := deleteEmptyFolders(backend, conf, new(bytes.Buffer))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"libs-local/a", "tools-local/c"}, backend.getDeleted())
+}
+
+func TestDeleteBrokenFilesFlow(t *testing.T) {
+	backend := newFakeBackend(t, []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder"},
+		{Path: "repo/a/lib.jar", Type: "file", Size: 10},
+		{Path: "repo/a/lib.jar.sha1", Type: "file", Size: 40},
+		{Path: "repo/a/old.jar.md5", Type: "file", Size: 32},
+		{Path: "repo/a/empty.txt", Type: "file", Size: 0},
+		{Path: "repo/keep/.gitkeep", Type: "file", Size: 0},
+	})
+	backend.failingPaths = map[string]bool{"repo/a/empty.txt": true}
+	excludePatterns, err := parseExcludePatterns("repo/keep/*")
+	assert.NoError(t, err)
+	conf := &filesConfiguration{paths: []string{"repo/"}, quiet: true, format: textFormat, excludePatterns: excludePatterns, threads: 2}
+
+	assert.EqualError(t, deleteBrokenFiles(backend, conf, new(bytes.Buffer)), "failed deleting 1 of 2 files")
+	assert.Equal(t, []string{"repo/a/old.jar.md5"}, backend.getDeleted())
+	assert.Equal(t, []string{"repo/a", "repo/a/empty.txt", "repo/a/lib.jar", "repo/a/lib.jar.sha1", "repo/keep/.gitkeep"}, backend.getRemaining())
+}
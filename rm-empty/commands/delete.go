@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const defaultDeleteThreads = 3
+
+// The interval between the progress reports while deleting the folders.
+var progressInterval = 5 * time.Second
+
+// Deletes a single item from Artifactory.
+type deleteItemFunc func(item *clientrtutils.ResultItem) error
+
+// The outcome of deleting the items found.
+type deleteResult struct {
+	total   int
+	deleted int
+	// The items which failed to be deleted, sorted by their paths.
+	failures []itemFailure
+}
+
+type itemFailure struct {
+	path string
+	err  error
+}
+
+// Returns a deleteItemFunc, which deletes the item from the provided Artifactory server.
+func newDeleteItemFunc(rtDetails *config.ServerDetails) (deleteItemFunc, error) {
+	serviceManager, err := utils.CreateServiceManager(rtDetails, 3, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	artDetails := serviceManager.GetConfig().GetServiceDetails()
+	return func(item *clientrtutils.ResultItem) error {
+		deletePath, err := clientutils.BuildUrl(artDetails.GetUrl(), item.GetItemRelativePath(), make(map[string]string))
+		if err != nil {
+			return err
+		}
+		httpClientDetails := artDetails.CreateHttpClientDetails()
+		resp, body, err := serviceManager.Client().SendDelete(deletePath, nil, &httpClientDetails)
+		if err != nil {
+			return err
+		}
+		return errorutils.CheckResponseStatusWithBody(resp, body, http.StatusNoContent)
+	}, nil
+}
+
+// Returns the value of a number of threads flag, which must be positive.
+func getThreadsFlagValue(c *components.Context, flagName string) (int, error) {
+	threads, err := c.GetIntFlagValue(flagName)
+	if err != nil {
+		return 0, err
+	}
+	if threads < 1 {
+		return 0, errors.New(flagName + " must be a positive number")
+	}
+	return threads, nil
+}
+
+// Runs collect to write the items found into a writer, and returns a reader of the items written.
+func collectItems(collect func(writer *content.ContentWriter) error) (reader *content.ContentReader, err error) {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	if err != nil {
+		return
+	}
+	err = collect(writer)
+	// The writer needs to be closed before it can be read from.
+	if e := writer.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return
+	}
+	return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+}
+
+// Deletes the items in the reader after getting the confirmation of the users, unless quiet is true, and logs the result.
+// Returns the result of the deletion, which is nil if the users didn't allow it, and an error if any of the items
+// failed to be deleted.
+func confirmAndDeleteItems(reader *content.ContentReader, quiet bool, threads int, itemsName string, deleteFunc deleteItemFunc) (result *deleteResult, err error) {
+	// Get confirmation from the users before deleting the items.
+	allowDelete := quiet
+	if !quiet {
+		allowDelete, err = utils.ConfirmDelete(reader)
+	}
+	if err != nil || !allowDelete {
+		return
+	}
+	if result, err = deleteItems(reader, threads, itemsName, deleteFunc); err != nil {
+		return
+	}
+	logDeleteResult(result, itemsName)
+	if len(result.failures) > 0 {
+		return result, fmt.Errorf("failed deleting %d of %d %s", len(result.failures), result.total, itemsName)
+	}
+	return
+}
+
+// Deletes the items in the reader using the provided number of threads. The itemsName describes the items in the logs,
+// such as "empty folders".
+// The progress is logged every progressInterval. A failure to delete an item doesn't stop the deletion of the others.
+func deleteItems(reader *content.ContentReader, threads int, itemsName string, deleteFunc deleteItemFunc) (result *deleteResult, err error) {
+	result = new(deleteResult)
+	if result.total, err = reader.Length(); err != nil {
+		return
+	}
+
+	var done atomic.Int64
+	var deleted atomic.Int64
+	var failuresMutex sync.Mutex
+	items := make(chan *clientrtutils.ResultItem, threads)
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				if e := deleteFunc(item); e != nil {
+					log.Debug("Failed deleting", item.Path+":", e.Error())
+					failuresMutex.Lock()
+					result.failures = append(result.failures, itemFailure{path: item.Path, err: e})
+					failuresMutex.Unlock()
+				} else {
+					deleted.Add(1)
+				}
+				done.Add(1)
+			}
+		}()
+	}
+
+	// Report the progress until all the items are handled.
+	stopProgress := make(chan struct{})
+	progressStopped := make(chan struct{})
+	go func() {
+		defer close(progressStopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				log.Info("Deleted", deleted.Load(), "of", result.total, itemsName+".", done.Load()-deleted.Load(), "failed so far.")
+			case <-stopProgress:
+				return
+			}
+		}
+	}()
+
+	for item := new(clientrtutils.ResultItem); reader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		items <- item
+	}
+	close(items)
+	wg.Wait()
+	close(stopProgress)
+	<-progressStopped
+
+	result.deleted = int(deleted.Load())
+	sort.Slice(result.failures, func(i, j int) bool {
+		return result.failures[i].path < result.failures[j].path
+	})
+	return result, reader.GetError()
+}
+
+// Logs the number of items deleted, and the items which failed to be deleted with their errors.
+func logDeleteResult(result *deleteResult, itemsName string) {
+	log.Info("Deleted", result.deleted, "of", result.total, itemsName+".")
+	if len(result.failures) > 0 {
+		logFailures("Failed deleting", itemsName, result.failures)
+	}
+}
+
+// Logs the items which failed, with their errors.
+func logFailures(prefix, itemsName string, failures []itemFailure) {
+	log.Error(prefix, len(failures), itemsName+":")
+	for _, failure := range failures {
+		log.Error("  " + failure.path + ": " + failure.err.Error())
+	}
+}
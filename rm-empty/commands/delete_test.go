@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestFoldersToDelete() []clientrtutils.ResultItem {
+	return []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder"},
+		{Path: "repo/b", Type: "folder"},
+		{Path: "repo/c", Type: "folder"},
+		{Path: "repo/d", Type: "folder"},
+		{Path: "repo/e", Type: "folder"},
+	}
+}
+
+func TestDeleteFolders(t *testing.T) {
+	previousInterval := progressInterval
+	progressInterval = time.Millisecond
+	defer func() {
+		progressInterval = previousInterval
+	}()
+
+	var mutex sync.Mutex
+	var deletedPaths []string
+	deleteFolder := func(folder *clientrtutils.ResultItem) error {
+		if folder.Path == "repo/b" || folder.Path == "repo/d" {
+			return errors.New("forbidden")
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		deletedPaths = append(deletedPaths, folder.Path)
+		return nil
+	}
+
+	for _, threads := range []int{1, 3, 10} {
+		deletedPaths = nil
+		result, err := deleteItems(createTestReader(t, getTestFoldersToDelete()), threads, "empty folders", deleteFolder)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.total)
+		assert.Equal(t, 3, result.deleted)
+		assert.Equal(t, []itemFailure{{path: "repo/b", err: errors.New("forbidden")}, {path: "repo/d", err: errors.New("forbidden")}}, result.failures)
+		assert.ElementsMatch(t, []string{"repo/a", "repo/c", "repo/e"}, deletedPaths)
+	}
+}
+
+func TestDeleteFoldersEmpty(t *testing.T) {
+	result, err := deleteItems(createTestReader(t, nil), 3, "empty folders", func(folder *clientrtutils.ResultItem) error {
+		t.Error("unexpected delete of", folder.Path)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &deleteResult{}, result)
+}
+
+func TestNewDeleteFolderFunc(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mutex.Unlock()
+		if r.URL.Path == "/artifactory/repo/missing/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	deleteFolder, err := newDeleteItemFunc(&config.ServerDetails{ArtifactoryUrl: server.URL + "/artifactory/"})
+	assert.NoError(t, err)
+	assert.NoError(t, deleteFolder(&clientrtutils.ResultItem{Path: "repo/a/b", Type: "folder"}))
+	assert.Error(t, deleteFolder(&clientrtutils.ResultItem{Path: "repo/missing", Type: "folder"}))
+	assert.Contains(t, requests, "DELETE /artifactory/repo/a/b/")
+}
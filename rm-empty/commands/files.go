@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// The extensions of checksum and signature files, which are only meaningful next to the file they were created for.
+var sidecarExtensions = []string{".sha1", ".md5", ".asc"}
+
+func GetFilesCommand() components.Command {
+	return components.Command{
+		Name:        "files",
+		Description: "Remove zero-byte files and orphaned checksum and signature files under the specified paths in Artifactory",
+		Aliases:     []string{"fl"},
+		Arguments:   getFilesArguments(),
+		Flags:       getFilesFlags(),
+		EnvVars:     getEnvVars(),
+		Action:      filesCmd,
+	}
+}
+
+func getFilesArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "paths",
+			Description: "One or more paths in Artifactory, under which to remove the files. Each path should start with a repository. The repository may include wildcards, such as libs-*-local/, which are matched against the local repositories",
+		},
+	}
+}
+
+func getFilesFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag("server-id", "Artifactory server ID configured using the config command"),
+		components.NewBoolFlag("quiet", "Skip the delete confirmation message"),
+		components.NewBoolFlag("all-local-repos", "Remove the files of all the local repositories, instead of the provided paths"),
+		components.NewStringFlag("exclude", "A semicolon-separated list of path patterns, such as 'repo/placeholders/*;*/.keep'. Files matching these patterns are never deleted. The patterns are matched against the full path, starting with the repository"),
+		components.NewStringFlag("threads", "The number of files deleted in parallel", components.WithIntDefaultValue(defaultDeleteThreads)),
+		components.NewBoolFlag("dry-run", "Only list the files found, without deleting them"),
+		components.NewStringFlag("format", "The format of the dry run output. text, json and csv are the allowed values", components.WithStrDefaultValue(textFormat)),
+	}
+}
+
+type filesConfiguration struct {
+	paths []string
+	// Scan all the local repositories instead of the paths.
+	allLocalRepos bool
+	quiet         bool
+	dryRun        bool
+	format        string
+	// Files matching these full path patterns are never deleted.
+	excludePatterns []*regexp.Regexp
+	// The number of files deleted in parallel.
+	threads int
+}
+
+func filesCmd(c *components.Context) error {
+	allLocalRepos, err := getAllLocalReposFlagValue(c)
+	if err != nil {
+		return err
+	}
+	conf := &filesConfiguration{
+		paths:         c.Arguments,
+		allLocalRepos: allLocalRepos,
+		quiet:         c.GetBoolFlagValue("quiet"),
+		dryRun:        c.GetBoolFlagValue("dry-run"),
+	}
+	if conf.format, err = getFormatFlagValue(c, conf.dryRun); err != nil {
+		return err
+	}
+	if conf.excludePatterns, err = parseExcludePatterns(c.GetStringFlagValue("exclude")); err != nil {
+		return err
+	}
+	if conf.threads, err = getThreadsFlagValue(c, "threads"); err != nil {
+		return err
+	}
+
+	rtDetails, err := getRtDetails(c)
+	if err != nil {
+		return err
+	}
+	backend, err := newArtifactoryBackend(rtDetails)
+	if err != nil {
+		return err
+	}
+
+	return deleteBrokenFiles(backend, conf, os.Stdout)
+}
+
+// Deletes the zero-byte files and the orphaned sidecar files under the specified paths in Artifactory.
+// The files of all the paths are deleted together, after a single confirmation.
+// On a dry run, the files are printed to the output instead, with their sizes.
+func deleteBrokenFiles(backend rmEmptyBackend, conf *filesConfiguration, output io.Writer) (err error) {
+	targets, err := resolveTargets(conf.paths, conf.allLocalRepos, backend.getLocalRepositories)
+	if err != nil {
+		return
+	}
+
+	// Find the files under all the paths.
+	var total brokenFilesCount
+	brokenFilesReader, err := collectItems(func(brokenFilesWriter *content.ContentWriter) error {
+		for _, target := range targets {
+			found, e := searchBrokenFiles(backend, target, conf.excludePatterns, brokenFilesWriter)
+			if e != nil {
+				return fmt.Errorf("failed scanning %s: %w", target, e)
+			}
+			total.zeroByte += found.zeroByte
+			total.orphanedSidecars += found.orphanedSidecars
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := brokenFilesReader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+	log.Info("Found", total.zeroByte, "zero-byte files and", total.orphanedSidecars, "orphaned checksum and signature files.")
+
+	// On a dry run, only print the files found.
+	if conf.dryRun {
+		return printItems(brokenFilesReader, conf.format, true, output)
+	}
+	if total.zeroByte+total.orphanedSidecars == 0 {
+		return
+	}
+	_, err = confirmAndDeleteItems(brokenFilesReader, conf.quiet, conf.threads, "files", backend.deleteItem)
+	return
+}
+
+type brokenFilesCount struct {
+	zeroByte         int
+	orphanedSidecars int
+}
+
+// Finds the zero-byte files and the orphaned sidecar files under the path, and writes them into the brokenFilesWriter.
+func searchBrokenFiles(backend rmEmptyBackend, path string, excludePatterns []*regexp.Regexp, brokenFilesWriter *content.ContentWriter) (found brokenFilesCount, err error) {
+	log.Info("Searching for all files under", path)
+	reader, err := backend.search(path, false)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := reader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	// Sort the files by their folders, so that the files of each folder are read together.
+	sortedFilesReader, err := content.SortContentReaderByCalculatedKey(reader, getFolderSortKey, true)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := sortedFilesReader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	return filterBrokenFiles(sortedFilesReader, brokenFilesWriter, excludePatterns)
+}
+
+// Returns a sort key which places the files of each folder next to each other, before the files of its subfolders.
+func getFolderSortKey(record interface{}) (string, error) {
+	item := new(clientrtutils.ResultItem)
+	if err := content.ConvertToStruct(record, item); err != nil {
+		return "", err
+	}
+	// The NUL character sorts before the slash, so the files of a folder come before the files of its subfolders.
+	return path.Dir(item.Path) + "\x00" + path.Base(item.Path), nil
+}
+
+// Finds the zero-byte files and the orphaned sidecar files by scanning the files of each folder together, and writes
+// them into the brokenFilesWriter. The files must be sorted by their folders, using getFolderSortKey.
+func filterBrokenFiles(sortedFilesReader *content.ContentReader, brokenFilesWriter *content.ContentWriter, excludePatterns []*regexp.Regexp) (found brokenFilesCount, err error) {
+	var folder string
+	var folderFiles []*clientrtutils.ResultItem
+	flush := func() {
+		for _, file := range findBrokenFiles(folderFiles) {
+			if isExcludedFile(file, excludePatterns) {
+				continue
+			}
+			brokenFilesWriter.Write(file)
+			if file.Size == 0 {
+				found.zeroByte++
+			} else {
+				found.orphanedSidecars++
+			}
+		}
+		folderFiles = nil
+	}
+	for file := new(clientrtutils.ResultItem); sortedFilesReader.NextRecord(file) == nil; file = new(clientrtutils.ResultItem) {
+		if file.Type == "folder" {
+			continue
+		}
+		if fileFolder := path.Dir(file.Path); fileFolder != folder {
+			flush()
+			folder = fileFolder
+		}
+		folderFiles = append(folderFiles, file)
+	}
+	flush()
+	return found, sortedFilesReader.GetError()
+}
+
+// Returns the files of a single folder which are zero-byte, and the sidecar files whose main file is missing.
+// A sidecar file whose main file is deleted is orphaned as well, such as the checksums of a zero-byte file.
+func findBrokenFiles(folderFiles []*clientrtutils.ResultItem) (brokenFiles []*clientrtutils.ResultItem) {
+	byName := make(map[string]*clientrtutils.ResultItem, len(folderFiles))
+	for _, file := range folderFiles {
+		byName[path.Base(file.Path)] = file
+	}
+	// Handle the shorter names first, so that the main file of each sidecar is handled before it.
+	sorted := append([]*clientrtutils.ResultItem(nil), folderFiles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(path.Base(sorted[i].Path)) < len(path.Base(sorted[j].Path))
+	})
+	broken := make(map[string]bool)
+	for _, file := range sorted {
+		name := path.Base(file.Path)
+		if file.Size == 0 {
+			broken[name] = true
+		} else if mainName, isSidecar := getSidecarMainName(name); isSidecar {
+			broken[name] = byName[mainName] == nil || broken[mainName]
+		}
+	}
+	for _, file := range folderFiles {
+		if broken[path.Base(file.Path)] {
+			brokenFiles = append(brokenFiles, file)
+		}
+	}
+	return
+}
+
+// Returns the name of the file which the sidecar file was created for, if the name is of a sidecar file.
+func getSidecarMainName(name string) (mainName string, isSidecar bool) {
+	for _, extension := range sidecarExtensions {
+		if mainName, isSidecar = strings.CutSuffix(name, extension); isSidecar && mainName != "" {
+			return
+		}
+	}
+	return "", false
+}
+
+func isExcludedFile(file *clientrtutils.ResultItem, excludePatterns []*regexp.Regexp) bool {
+	for _, pattern := range excludePatterns {
+		if pattern.MatchString(file.Path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSidecarMainName(t *testing.T) {
+	var names = []struct {
+		name      string
+		mainName  string
+		isSidecar bool
+	}{
+		{"app-1.0.jar.sha1", "app-1.0.jar", true},
+		{"app-1.0.jar.md5", "app-1.0.jar", true},
+		{"app-1.0.jar.asc", "app-1.0.jar", true},
+		{"app-1.0.jar.asc.md5", "app-1.0.jar.asc", true},
+		{"app-1.0.jar", "", false},
+		{".sha1", "", false},
+	}
+	for _, v := range names {
+		mainName, isSidecar := getSidecarMainName(v.name)
+		assert.Equal(t, v.mainName, mainName, "getSidecarMainName(%q)", v.name)
+		assert.Equal(t, v.isSidecar, isSidecar, "getSidecarMainName(%q)", v.name)
+	}
+}
+
+func TestFindBrokenFiles(t *testing.T) {
+	folderFiles := []*clientrtutils.ResultItem{
+		{Path: "repo/a/app-1.0.jar", Type: "file", Size: 10},
+		{Path: "repo/a/app-1.0.jar.sha1", Type: "file", Size: 40},
+		{Path: "repo/a/app-1.0.jar.asc", Type: "file", Size: 30},
+		{Path: "repo/a/app-1.0.pom.sha1", Type: "file", Size: 40},
+		{Path: "repo/a/app-1.0.pom.asc.md5", Type: "file", Size: 32},
+		{Path: "repo/a/app-1.0.war", Type: "file", Size: 0},
+		{Path: "repo/a/app-1.0.war.md5", Type: "file", Size: 32},
+		{Path: "repo/a/empty.sha1", Type: "file", Size: 0},
+	}
+	var brokenPaths []string
+	for _, file := range findBrokenFiles(folderFiles) {
+		brokenPaths = append(brokenPaths, file.Path)
+	}
+	assert.Equal(t, []string{
+		"repo/a/app-1.0.pom.sha1",
+		"repo/a/app-1.0.pom.asc.md5",
+		"repo/a/app-1.0.war",
+		"repo/a/app-1.0.war.md5",
+		"repo/a/empty.sha1",
+	}, brokenPaths)
+}
+
+func TestFilterBrokenFiles(t *testing.T) {
+	items := []clientrtutils.ResultItem{
+		{Path: "repo/a/lib.jar", Type: "file", Size: 10},
+		{Path: "repo/a/b/lib.jar.sha1", Type: "file", Size: 40},
+		{Path: "repo/a/lib.jar.sha1", Type: "file", Size: 40},
+		{Path: "repo/a/b/empty.txt", Type: "file", Size: 0},
+		{Path: "repo/a-b/lib.jar.md5", Type: "file", Size: 32},
+		{Path: "repo/keep/.gitkeep", Type: "file", Size: 0},
+		{Path: "repo/c", Type: "folder"},
+	}
+	reader := createTestReader(t, items)
+	sortedReader, err := content.SortContentReaderByCalculatedKey(reader, getFolderSortKey, true)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sortedReader.Close())
+	}()
+
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	found, err := filterBrokenFiles(sortedReader, writer, []*regexp.Regexp{regexp.MustCompile(`^repo/keep/.*$`)})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, brokenFilesCount{zeroByte: 1, orphanedSidecars: 2}, found)
+
+	brokenReader := content.NewContentReader(writer.GetFilePath(), content.DefaultKey)
+	defer func() {
+		assert.NoError(t, brokenReader.Close())
+	}()
+	var brokenPaths []string
+	for item := new(clientrtutils.ResultItem); brokenReader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		brokenPaths = append(brokenPaths, item.Path)
+	}
+	assert.NoError(t, brokenReader.GetError())
+	sort.Strings(brokenPaths)
+	assert.Equal(t, []string{"repo/a-b/lib.jar.md5", "repo/a/b/empty.txt", "repo/a/b/lib.jar.sha1"}, brokenPaths)
+}
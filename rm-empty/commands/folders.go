@@ -2,19 +2,22 @@ package commands
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
 	"github.com/jfrog/jfrog-cli-core/v2/common/commands"
-	"github.com/jfrog/jfrog-cli-core/v2/common/spec"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
 	clientutils "github.com/jfrog/jfrog-client-go/utils"
 	"github.com/jfrog/jfrog-client-go/utils/io/content"
 	"github.com/jfrog/jfrog-client-go/utils/log"
 
-	"github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/generic"
 	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
 )
 
@@ -35,8 +38,8 @@ func GetCleanCommand() components.Command {
 func getArguments() []components.Argument {
 	return []components.Argument{
 		{
-			Name:        "path",
-			Description: "Path in Artifactory. The path should start with a repository",
+			Name:        "paths",
+			Description: "One or more paths in Artifactory. Each path should start with a repository. The repository may include wildcards, such as libs-*-local/, which are matched against the local repositories",
 		},
 	}
 }
@@ -45,6 +48,23 @@ func getFlags() []components.Flag {
 	return []components.Flag{
 		components.NewStringFlag("server-id", "Artifactory server ID configured using the config command"),
 		components.NewBoolFlag("quiet", "Skip the delete confirmation message"),
+		components.NewBoolFlag("all-local-repos", "Remove the empty folders of all the local repositories, instead of the provided paths"),
+		components.NewStringFlag("min-age", "Only delete empty folders which were created and modified at least min-age ago, such as 30m, 1h or 7d. Protects folders of uploads in progress"),
+		components.NewStringFlag("exclude", "A semicolon-separated list of path patterns, such as 'repo/placeholders/*;*/.keep'. Folders matching these patterns are never deleted, even when empty. The patterns are matched against the full path, starting with the repository"),
+		components.NewStringFlag("exclude-props", "A list of properties in the key1=value1,value2;key2=value3 format. Folders holding one of these properties are never deleted, even when empty"),
+		components.NewStringFlag("min-depth", "Only delete empty folders at least min-depth levels below the provided path. Shallower folders are kept even when empty. For example, 2 keeps the folders directly under the path"),
+		components.NewStringFlag("max-depth", "Only delete empty folders up to max-depth levels below the provided path. Deeper empty folders are only deleted together with an empty ancestor"),
+		components.NewStringFlag("ignore-files", "A comma-separated list of file name patterns, such as 'maven-metadata.xml*,*.md5'. A folder holding only matching files is considered empty, and the files are deleted together with it"),
+		components.NewStringFlag("scan-strategy", "The strategy used to find the empty folders. search runs a single search for all the items under the path and sorts them on disk. levels walks the folder hierarchy level by level with paged queries, and is better suited for huge repositories", components.WithStrDefaultValue(searchStrategy)),
+		components.NewStringFlag("scan-threads", "The number of folders listed in parallel by the levels scan-strategy", components.WithIntDefaultValue(defaultScanThreads)),
+		components.NewStringFlag("threads", "The number of folders deleted in parallel", components.WithIntDefaultValue(defaultDeleteThreads)),
+		components.NewStringFlag("manifest", "A path of a file to save the manifest of the deleted folders and their properties into. The folders can be restored later using the restore command"),
+		components.NewBoolFlag("dry-run", "Only list the empty folders found, without deleting them"),
+		components.NewStringFlag("format", "The format of the dry run output. text, json and csv are the allowed values", components.WithStrDefaultValue(textFormat)),
+		components.NewBoolFlag("watch", "Keep running, and remove the empty folders again every interval, until an interrupt or a termination signal is received. Implies the quiet flag"),
+		components.NewStringFlag("interval", "The time between the runs in watch mode, such as 30m, 12h or 1d", components.WithStrDefaultValue(defaultWatchInterval)),
+		components.NewStringFlag("history", "A path of a file to append a JSON summary of each run to. In watch mode, defaults to "+defaultHistoryFileName+" in the JFrog CLI home directory"),
+		components.NewBoolFlag("detailed-exit-code", "Exit with 0 if no empty folders were found, 10 if empty folders were deleted or found on a dry run, 11 if some of them failed to be deleted, 12 if the deletion wasn't confirmed, and 1 on any other failure"),
 	}
 }
 
@@ -55,78 +75,229 @@ func getEnvVars() []components.EnvVar {
 }
 
 func foldersCmd(c *components.Context) error {
-	if len(c.Arguments) != 1 {
-		return errors.New("wrong number of arguments. Expected: 1, " + "Received: " + strconv.Itoa(len(c.Arguments)))
+	allLocalRepos, err := getAllLocalReposFlagValue(c)
+	if err != nil {
+		return err
+	}
+	conf := &foldersConfiguration{
+		paths:         c.Arguments,
+		allLocalRepos: allLocalRepos,
+		quiet:         c.GetBoolFlagValue("quiet"),
+		dryRun:        c.GetBoolFlagValue("dry-run"),
+	}
+	if conf.format, err = getFormatFlagValue(c, conf.dryRun); err != nil {
+		return err
+	}
+	if conf.manifestPath = c.GetStringFlagValue("manifest"); conf.manifestPath != "" && conf.dryRun {
+		return errors.New("the manifest flag cannot be used together with the dry-run flag")
 	}
 
-	rtDetails, err := getRtDetails(c)
+	if c.IsFlagSet("min-age") {
+		minAge, err := parseAge(c.GetStringFlagValue("min-age"))
+		if err != nil {
+			return errors.New("wrong min-age value: " + err.Error())
+		}
+		if minAge < 0 {
+			return errors.New("min-age cannot be negative")
+		}
+		conf.minAge = minAge
+	}
+
+	ignoreFiles, err := parseIgnoreFiles(c.GetStringFlagValue("ignore-files"))
 	if err != nil {
 		return err
 	}
+	conf.ignoreFiles = ignoreFiles
 
-	path := c.Arguments[0]
-	return deleteEmptyFolders(rtDetails, path, c.GetBoolFlagValue("quiet"))
-}
+	if conf.excludePatterns, err = parseExcludePatterns(c.GetStringFlagValue("exclude")); err != nil {
+		return err
+	}
+	if conf.excludeProps, err = parseExcludeProps(c.GetStringFlagValue("exclude-props")); err != nil {
+		return err
+	}
 
-// Deletes all the empty folders under the specified path in Artifactory.
-func deleteEmptyFolders(rtDetails *config.ServerDetails, path string, quiet bool) (err error) {
-	// Create a search command, to find all the files and folders under the specified path.
-	spec := spec.NewBuilder().Pattern(path).IncludeDirs(true).Recursive(true).BuildSpec()
-	cmd := generic.NewSearchCommand()
-	cmd.SetServerDetails(rtDetails).SetSpec(spec).SetRetries(3)
+	if conf.scanStrategy = strings.ToLower(c.GetStringFlagValue("scan-strategy")); conf.scanStrategy != searchStrategy && conf.scanStrategy != levelsStrategy {
+		return errors.New("wrong scan-strategy. Expected: search or levels. Received: " + conf.scanStrategy)
+	}
+	if conf.scanStrategy == levelsStrategy {
+		for _, path := range conf.paths {
+			// Wildcards in the repository are resolved before the scan.
+			if _, pathInRepo, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/"); strings.Contains(pathInRepo, "*") {
+				return errors.New("wildcards are only supported in the repository of the path by the levels scan-strategy")
+			}
+		}
+	}
+	if conf.scanThreads, err = getThreadsFlagValue(c, "scan-threads"); err != nil {
+		return err
+	}
 
-	log.Info("Searching for all items under", path)
+	if conf.minDepth, err = getDepthFlagValue(c, "min-depth"); err != nil {
+		return err
+	}
+	if conf.maxDepth, err = getDepthFlagValue(c, "max-depth"); err != nil {
+		return err
+	}
+	if conf.maxDepth > 0 && conf.minDepth > conf.maxDepth {
+		return errors.New("min-depth cannot be greater than max-depth")
+	}
 
-	// Run the search and receive a reader with the results.
-	var reader *content.ContentReader
-	if reader, err = cmd.Search(); err != nil {
-		return
+	if err = setWatchConfiguration(c, conf); err != nil {
+		return err
 	}
-	defer func() {
-		e := reader.Close()
-		if err == nil {
-			err = e
+	if conf.detailedExitCode = c.GetBoolFlagValue("detailed-exit-code"); conf.detailedExitCode && conf.watch {
+		return errors.New("the detailed-exit-code flag cannot be used together with the watch flag")
+	}
+
+	if conf.threads, err = getThreadsFlagValue(c, "threads"); err != nil {
+		return err
+	}
+
+	rtDetails, err := getRtDetails(c)
+	if err != nil {
+		return err
+	}
+	backend, err := newArtifactoryBackend(rtDetails)
+	if err != nil {
+		return err
+	}
+
+	if conf.watch {
+		ctx, stop := newSignalContext()
+		defer stop()
+		return watchEmptyFolders(ctx, conf.interval, conf.historyPath, func() (*runSummary, error) {
+			return deleteEmptyFolders(backend, conf, os.Stdout)
+		})
+	}
+	summary, err := deleteEmptyFolders(backend, conf, os.Stdout)
+	recordRun(summary, err, conf.historyPath)
+	if conf.detailedExitCode {
+		return getDetailedExitError(summary, err)
+	}
+	return err
+}
+
+// Reads the watch, interval and history flags into the configuration.
+func setWatchConfiguration(c *components.Context, conf *foldersConfiguration) (err error) {
+	conf.watch = c.GetBoolFlagValue("watch")
+	conf.historyPath = c.GetStringFlagValue("history")
+	if !conf.watch {
+		if c.IsFlagSet("interval") {
+			return errors.New("the interval flag can only be used together with the watch flag")
 		}
-	}()
+		return nil
+	}
+	// The runs can't be confirmed in watch mode.
+	conf.quiet = true
+	if conf.manifestPath != "" {
+		return errors.New("the manifest flag cannot be used together with the watch flag")
+	}
+	if conf.interval, err = parseAge(c.GetStringFlagValue("interval")); err != nil {
+		return errors.New("wrong interval value: " + err.Error())
+	}
+	if conf.interval <= 0 {
+		return errors.New("interval must be a positive duration")
+	}
+	if conf.historyPath == "" {
+		conf.historyPath, err = getDefaultHistoryPath()
+	}
+	return
+}
 
-	// Sort the results in the reader, so that the empty folders can be found by reading the results
-	// record by record.
-	var sortedFilesReader *content.ContentReader
-	if sortedFilesReader, err = content.SortContentReader(clientrtutils.ResultItem{}, reader, true); err != nil {
-		return
+type foldersConfiguration struct {
+	paths []string
+	// Scan all the local repositories instead of the paths.
+	allLocalRepos bool
+	quiet         bool
+	dryRun        bool
+	format        string
+	// Empty folders created or modified during the last minAge are not deleted.
+	minAge time.Duration
+	// Folders matching these full path patterns are never deleted.
+	excludePatterns []*regexp.Regexp
+	// Folders holding one of these properties are never deleted.
+	excludeProps map[string][]string
+	// Name patterns of files that do not prevent a folder from being considered empty.
+	ignoreFiles []string
+	// The strategy used to find the empty folders, search or levels.
+	scanStrategy string
+	// The maximum number of folders listed in parallel by the levels strategy.
+	scanThreads int
+	// The number of folders deleted in parallel.
+	threads int
+	// The path of the manifest file to record the deleted folders in. No manifest is saved if empty.
+	manifestPath string
+	// The depth limits of the folders deleted, relative to each path. Ignored if zero.
+	minDepth int
+	maxDepth int
+	// Keep running, and delete the empty folders again every interval.
+	watch    bool
+	interval time.Duration
+	// The path of the file to append the summary of each run to. No history is kept if empty.
+	historyPath string
+	// Exit with a distinct code for each outcome of the run.
+	detailedExitCode bool
+}
+
+// Returns the value of a depth flag, or zero if the flag isn't set.
+func getDepthFlagValue(c *components.Context, flagName string) (int, error) {
+	if !c.IsFlagSet(flagName) {
+		return 0, nil
+	}
+	depth, err := c.GetIntFlagValue(flagName)
+	if err != nil {
+		return 0, err
 	}
+	if depth < 1 {
+		return 0, errors.New(flagName + " must be a positive number")
+	}
+	return depth, nil
+}
 
-	// Create a writer, that will be used to store the paths of the empty folders found.
-	var emptyFoldersWriter *content.ContentWriter
-	emptyFoldersWriter, err = content.NewContentWriter(content.DefaultKey, true, false)
-	emptyFoldersReaderClosed := false
+// Deletes all the empty folders under the specified paths in Artifactory.
+// The empty folders of all the paths are deleted together, after a single confirmation.
+// On a dry run, the empty folders are printed to the output instead.
+// Returns the summary of the run, which is filled as far as the run got, even if it failed.
+func deleteEmptyFolders(backend rmEmptyBackend, conf *foldersConfiguration, output io.Writer) (summary *runSummary, err error) {
+	start := time.Now()
+	summary = &runSummary{Started: start.UTC().Format(time.RFC3339), DryRun: conf.dryRun}
 	defer func() {
-		if !emptyFoldersReaderClosed {
-			e := emptyFoldersWriter.Close()
-			if err == nil {
-				err = e
-			}
-		}
+		summary.DurationSeconds = time.Since(start).Seconds()
 	}()
 
-	// Find all empty folders by scanning the sortedFilesReader, and write them into the emptyFoldersWriter.
-	var totalFound int
-	totalFound, err = filterEmptyFolders(sortedFilesReader, emptyFoldersWriter)
+	var targets []string
+	targets, err = resolveTargets(conf.paths, conf.allLocalRepos, backend.getLocalRepositories)
 	if err != nil {
 		return
 	}
+	summary.Paths = targets
 
-	logEmptyFoldersFound(totalFound)
-
-	// The writer needs to be closed before it cam be read from.
-	err = emptyFoldersWriter.Close()
+	// Find all empty folders under the paths.
+	var total scanCount
+	rules := newFolderRules(conf, time.Now())
+	emptyFoldersReader, err := collectItems(func(emptyFoldersWriter *content.ContentWriter) error {
+		for _, target := range targets {
+			var found scanCount
+			var e error
+			targetRules := rules.forRoot(target)
+			if conf.scanStrategy == levelsStrategy {
+				found, e = scanLevels(backend, target, targetRules, conf.scanThreads, emptyFoldersWriter)
+			} else {
+				found, e = searchEmptyFolders(backend, target, targetRules, emptyFoldersWriter)
+			}
+			if e != nil {
+				return fmt.Errorf("failed scanning %s: %w", target, e)
+			}
+			if len(targets) > 1 {
+				log.Info("Found", found.found, "empty folders under", target)
+			}
+			total.scanned += found.scanned
+			total.found += found.found
+		}
+		return nil
+	})
 	if err != nil {
 		return
 	}
-	emptyFoldersReaderClosed = true
-
-	// Create a reader from the writer, to read all the empty folders found.
-	emptyFoldersReader := content.NewContentReader(emptyFoldersWriter.GetFilePath(), content.DefaultKey)
 	defer func() {
 		e := emptyFoldersReader.Close()
 		if err == nil {
@@ -134,6 +305,15 @@ func deleteEmptyFolders(rtDetails *config.ServerDetails, path string, quiet bool
 		}
 	}()
 
+	logEmptyFoldersFound(total.found, len(targets))
+	summary.Scanned = total.scanned
+	summary.Found = total.found
+
+	// On a dry run, only print the folders found.
+	if conf.dryRun {
+		return summary, printItems(emptyFoldersReader, conf.format, false, output)
+	}
+
 	var length int
 	length, err = emptyFoldersReader.Length()
 	if err != nil {
@@ -144,39 +324,181 @@ func deleteEmptyFolders(rtDetails *config.ServerDetails, path string, quiet bool
 	}
 
 	// Delete the folders in the reader.
-	return deleteItem(emptyFoldersReader, rtDetails, quiet)
+	result, err := deleteItem(emptyFoldersReader, backend, conf)
+	if result != nil {
+		summary.Deleted = result.deleted
+		summary.Failed = len(result.failures)
+	} else if err == nil {
+		summary.NotConfirmed = true
+	}
+	return
+}
+
+// Finds the empty folders under the path by searching for all the items under it at once, and sorting the results on disk.
+func searchEmptyFolders(backend rmEmptyBackend, path string, rules *folderRules, emptyFoldersWriter *content.ContentWriter) (count scanCount, err error) {
+	log.Info("Searching for all items under", path)
+
+	// Search for all the files and folders under the specified path, and receive a reader with the results.
+	var reader *content.ContentReader
+	if reader, err = backend.search(path, true); err != nil {
+		return
+	}
+	defer func() {
+		e := reader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	// Sort the results in the reader, so that the empty folders can be found by reading the results
+	// record by record.
+	var sortedFilesReader *content.ContentReader
+	if sortedFilesReader, err = content.SortContentReaderByCalculatedKey(reader, getTreeSortKey, true); err != nil {
+		return
+	}
+	defer func() {
+		e := sortedFilesReader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	// Find all empty folders by scanning the sortedFilesReader, and write them into the emptyFoldersWriter.
+	return filterEmptyFolders(sortedFilesReader, emptyFoldersWriter, rules)
 }
 
-func logEmptyFoldersFound(totalFound int) {
+func logEmptyFoldersFound(totalFound, targetsCount int) {
+	suffix := "."
+	if targetsCount > 1 {
+		suffix = " in " + strconv.Itoa(targetsCount) + " paths."
+	}
 	switch totalFound {
 	case 0:
-		log.Info("Found no empty folders.")
+		log.Info("Found no empty folders" + suffix)
 	case 1:
-		log.Info("Found 1 empty folder.")
+		log.Info("Found 1 empty folder" + suffix)
 	default:
-		log.Info("Found", totalFound, "empty folders.")
+		log.Info("Found", totalFound, "empty folders"+suffix)
+	}
+}
+
+// The number of items read while scanning a path, and the number of empty folders found among them.
+type scanCount struct {
+	scanned int
+	found   int
+}
+
+// An item returned by the search command. The properties of the item are returned as a map.
+type searchResultItem struct {
+	clientrtutils.ResultItem
+	Props map[string][]string `json:"props,omitempty"`
+}
+
+// Returns the ResultItem of the search result, holding its properties.
+func (r *searchResultItem) toResultItem() *clientrtutils.ResultItem {
+	item := r.ResultItem
+	keys := make([]string, 0, len(r.Props))
+	for key := range r.Props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range r.Props[key] {
+			item.Properties = append(item.Properties, clientrtutils.Property{Key: key, Value: value})
+		}
 	}
+	return &item
+}
+
+// Returns a sort key which places each folder right before its subtree, by comparing the paths segment by segment.
+// Comparing the paths as plain strings places repo/a-b between repo/a and repo/a/c, since '-' sorts before '/'.
+func getTreeSortKey(record interface{}) (string, error) {
+	item := new(clientrtutils.ResultItem)
+	if err := content.ConvertToStruct(record, item); err != nil {
+		return "", err
+	}
+	// The NUL character sorts before any character of a name, so the subtree of a folder comes before its siblings.
+	return strings.ReplaceAll(strings.Trim(item.Path, "/"), "/", "\x00"), nil
+}
+
+// Returns true if the item is in the subtree of the folder. The paths are compared segment by segment,
+// so that repo/ab is not considered to be in repo/a.
+func isInFolder(itemPath, folderPath string) bool {
+	return strings.HasPrefix(itemPath, strings.Trim(folderPath, "/")+"/")
+}
+
+// A folder on the path from the root of the scan to the current item, while scanning the sorted results.
+type folderFrame struct {
+	folder *clientrtutils.ResultItem
+	// True if the subtree of the folder contains files, or folders that must be kept.
+	keep bool
+	// The empty subfolders found so far, while it is still unknown whether the folder itself is empty.
+	emptySubfolders []*clientrtutils.ResultItem
 }
 
 // Find all empty folders by scanning the sortedFilesReader, and write them into the emptyFoldersWriter.
-func filterEmptyFolders(sortedFilesReader *content.ContentReader, emptyFoldersWriter *content.ContentWriter) (totalFound int, err error) {
-	var prevFolder *clientrtutils.ResultItem
-	for item := new(clientrtutils.ResultItem); sortedFilesReader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
-		if prevFolder != nil && !strings.HasPrefix(item.Path, prevFolder.Path) {
-			emptyFoldersWriter.Write(prevFolder)
-			totalFound++
+// A folder is empty if its entire subtree holds no files, other than files ignored by the rules. Only the highest folder of each empty subtree is written,
+// since deleting it removes the entire subtree.
+// The results are sorted using getTreeSortKey, so the subtree of each folder is read right after the folder itself. This allows finding
+// the empty subtrees bottom-up, by keeping only the folders on the path to the current item in a stack.
+// Folders which must be kept according to the rules are never written, and neither are their ancestors.
+// Empty folders which may not be deleted themselves according to the rules are not written either, but they don't
+// prevent their ancestors from being written.
+func filterEmptyFolders(sortedFilesReader *content.ContentReader, emptyFoldersWriter *content.ContentWriter, rules *folderRules) (count scanCount, err error) {
+	var stack []*folderFrame
+	writeEmptyFolder := func(folder *clientrtutils.ResultItem) {
+		if !rules.deletable(folder) {
+			return
+		}
+		emptyFoldersWriter.Write(folder)
+		count.found++
+	}
+	// Marks the folder at index i of the stack and all its ancestors as folders to keep.
+	// The empty subfolders of these folders are the highest in their subtrees, so they are written right away.
+	markKeep := func(i int) {
+		for ; i >= 0 && !stack[i].keep; i-- {
+			stack[i].keep = true
+			for _, emptySubfolder := range stack[i].emptySubfolders {
+				writeEmptyFolder(emptySubfolder)
+			}
+			stack[i].emptySubfolders = nil
+		}
+	}
+	// Pops the folder at the top of the stack, once its entire subtree was read.
+	pop := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.keep {
+			return
+		}
+		// The folder is empty. Its empty subfolders will be deleted together with it.
+		if len(stack) == 0 || stack[len(stack)-1].keep {
+			writeEmptyFolder(top.folder)
+			return
+		}
+		parent := stack[len(stack)-1]
+		parent.emptySubfolders = append(parent.emptySubfolders, top.folder)
+	}
+
+	for record := new(searchResultItem); sortedFilesReader.NextRecord(record) == nil; record = new(searchResultItem) {
+		item := record.toResultItem()
+		count.scanned++
+		for len(stack) > 0 && !isInFolder(item.Path, stack[len(stack)-1].folder.Path) {
+			pop()
 		}
-		if item.Type == "folder" && !isRepo(item.Path) {
-			prevFolder = item
-		} else {
-			prevFolder = nil
+		if item.Type == "folder" {
+			stack = append(stack, &folderFrame{folder: item})
+			if rules.keepFolder(item) {
+				markKeep(len(stack) - 1)
+			}
+		} else if !rules.ignoreFile(item) {
+			markKeep(len(stack) - 1)
 		}
 	}
-	if prevFolder != nil {
-		emptyFoldersWriter.Write(prevFolder)
-		totalFound++
+	for len(stack) > 0 {
+		pop()
 	}
-	return totalFound, sortedFilesReader.GetError()
+	return count, sortedFilesReader.GetError()
 }
 
 // Returns true if the provided path leads to the root of a repository.
@@ -189,23 +511,29 @@ func isRepo(path string) bool {
 }
 
 // Deletes the paths sent in the provided reader from the provided Artifactory server.
-func deleteItem(reader *content.ContentReader, rtDetails *config.ServerDetails, quiet bool) (err error) {
-	// Create a delete command
-	cmd := generic.NewDeleteCommand()
-	cmd.SetServerDetails(rtDetails)
-
-	// Get confirmation from the users before deleted the paths.
-	allowDelete := quiet
-	if !quiet {
-		allowDelete, err = utils.ConfirmDelete(reader)
-	}
-	if err != nil || !allowDelete {
-		return
+// If a manifest path is configured, the deleted folders are recorded in the manifest.
+// Returns the result of the deletion, which is nil if the users didn't allow it, and an error if any of the paths
+// failed to be deleted.
+func deleteItem(reader *content.ContentReader, backend rmEmptyBackend, conf *foldersConfiguration) (result *deleteResult, err error) {
+	deleteFolder := deleteItemFunc(backend.deleteItem)
+	if conf.manifestPath != "" {
+		recorder := newManifestRecorder(backend.execAql)
+		deleteFolder = recorder.wrap(deleteFolder)
+		// Save the manifest of the folders deleted, even if the deletion was interrupted.
+		defer func() {
+			// The users didn't allow the deletion.
+			if result == nil {
+				return
+			}
+			m := recorder.manifest(backend.getArtifactoryUrl(), time.Now())
+			if e := saveManifest(m, conf.manifestPath); e != nil {
+				err = errors.Join(err, fmt.Errorf("failed saving the manifest: %w", e))
+				return
+			}
+			log.Info("Saved the manifest of", len(m.Folders), "deleted folders to", conf.manifestPath)
+		}()
 	}
-
-	// Delete the paths from Artifactory.
-	_, _, err = cmd.DeleteFiles(reader)
-	return
+	return confirmAndDeleteItems(reader, conf.quiet, conf.threads, "empty folders", deleteFolder)
 }
 
 // Returns the Artifactory Details of the provided server-id, or the default one.
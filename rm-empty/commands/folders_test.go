@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
 	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
 	"github.com/jfrog/jfrog-client-go/utils/io/content"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestFilterEmptyFolders(t *testing.T) {
@@ -32,7 +36,7 @@ func TestFilterEmptyFolders(t *testing.T) {
 	}()
 
 	// Sort the test in resultsReader into sortedResultsReader.
-	sortedResultsReader, err := content.SortContentReader(clientrtutils.ResultItem{}, resultsReader, true)
+	sortedResultsReader, err := content.SortContentReaderByCalculatedKey(resultsReader, getTreeSortKey, true)
 	assert.NoError(t, err)
 	defer func() {
 		assert.NoError(t, sortedResultsReader.Close())
@@ -50,8 +54,8 @@ func TestFilterEmptyFolders(t *testing.T) {
 
 	// Run the filterEmptyFolders function, which writes all the empty folders in sortedResultsReader
 	// into emptyFoldersWriter.
-	totalFound, err := filterEmptyFolders(sortedResultsReader, emptyFoldersWriter)
-	assert.Equal(t, 2, totalFound)
+	count, err := filterEmptyFolders(sortedResultsReader, emptyFoldersWriter, new(folderRules))
+	assert.Equal(t, scanCount{scanned: 5, found: 2}, count)
 	assert.NoError(t, err)
 
 	// Close the writer now, so that we can read its content using a reader.
@@ -111,3 +115,221 @@ func getTestEmptyFolders() []clientrtutils.ResultItem {
 		{Path: "a/b/c/d", Type: "folder"},
 	}
 }
+
+func TestFilterEmptyFolderTrees(t *testing.T) {
+	items := []clientrtutils.ResultItem{
+		// An empty chain of folders is deleted through its highest folder.
+		{Path: "repo/x", Type: "folder"},
+		{Path: "repo/x/y", Type: "folder"},
+		{Path: "repo/x/y/z", Type: "folder"},
+		{Path: "repo/x/w", Type: "folder"},
+		// A folder holding a file is kept, but its empty subtrees are deleted.
+		{Path: "repo/a", Type: "folder"},
+		{Path: "repo/a/b", Type: "folder"},
+		{Path: "repo/a/b/c", Type: "folder"},
+		{Path: "repo/a/d", Type: "folder"},
+		{Path: "repo/a/d/e", Type: "folder"},
+		{Path: "repo/a/d/e/file.txt", Type: "file"},
+		{Path: "repo/a/d/f", Type: "folder"},
+		// The repository itself is never deleted.
+		{Path: "repo/", Type: "folder"},
+	}
+	assert.Equal(t, []string{"repo/a/b", "repo/a/d/f", "repo/x"}, runFilterEmptyFolders(t, items, new(folderRules)))
+}
+
+// Folders whose names share a prefix with their siblings, or hold unicode and slash-like characters.
+func getTestSimilarNameItems() []clientrtutils.ResultItem {
+	return []clientrtutils.ResultItem{
+		{Path: "org/foo", Type: "folder"},
+		{Path: "org/foo/x", Type: "folder"},
+		{Path: "org/foo-bar", Type: "folder"},
+		{Path: "org/foo-bar/file.txt", Type: "file"},
+		{Path: "org/foo.txt", Type: "file"},
+		{Path: "org/foobar", Type: "folder"},
+		{Path: "org/données", Type: "folder"},
+		{Path: "org/donnée", Type: "folder"},
+		{Path: "org/donnée/file.txt", Type: "file"},
+		{Path: "org/日本", Type: "folder"},
+		{Path: "org/日本/語", Type: "folder"},
+		{Path: "org/日本語", Type: "folder"},
+		{Path: "org/日本語/file.txt", Type: "file"},
+		// U+2215 DIVISION SLASH, a backslash and an encoded slash are parts of the names, not separators.
+		{Path: "org/a", Type: "folder"},
+		{Path: "org/a/file.txt", Type: "file"},
+		{Path: "org/a∕b", Type: "folder"},
+		{Path: "org/a\\b", Type: "folder"},
+		{Path: "org/a%2Fb", Type: "folder"},
+		{Path: "org/a%2Fb/file.txt", Type: "file"},
+	}
+}
+
+func getTestSimilarNameEmptyFolders() []string {
+	return []string{"org/a\\b", "org/a∕b", "org/données", "org/foo", "org/foobar", "org/日本"}
+}
+
+func TestFilterEmptyFoldersSimilarNames(t *testing.T) {
+	assert.Equal(t, getTestSimilarNameEmptyFolders(), runFilterEmptyFolders(t, getTestSimilarNameItems(), new(folderRules)))
+}
+
+func TestGetTreeSortKey(t *testing.T) {
+	paths := []string{"org/foo.txt", "org/foo-bar/file.txt", "org/foo/x", "org/foo", "org/foobar", "org/foo-bar"}
+	sort.Slice(paths, func(i, j int) bool {
+		keyI, err := getTreeSortKey(clientrtutils.ResultItem{Path: paths[i]})
+		assert.NoError(t, err)
+		keyJ, err := getTreeSortKey(clientrtutils.ResultItem{Path: paths[j]})
+		assert.NoError(t, err)
+		return keyI < keyJ
+	})
+	assert.Equal(t, []string{"org/foo", "org/foo/x", "org/foo-bar", "org/foo-bar/file.txt", "org/foo.txt", "org/foobar"}, paths)
+}
+
+func TestIsInFolder(t *testing.T) {
+	assert.True(t, isInFolder("org/foo/x", "org/foo"))
+	assert.True(t, isInFolder("org/foo/x", "org/foo/"))
+	assert.False(t, isInFolder("org/foo", "org/foo"))
+	assert.False(t, isInFolder("org/foobar", "org/foo"))
+	assert.False(t, isInFolder("org/foo-bar/x", "org/foo"))
+}
+
+func TestFilterEmptyFoldersMinAge(t *testing.T) {
+	old, recent := "2020-01-01T00:00:00.000Z", "2020-06-01T00:00:00.000Z"
+	items := []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder", Created: old, Modified: old},
+		{Path: "repo/a/b", Type: "folder", Created: old, Modified: old},
+		// A recent folder is kept, and so are its ancestors.
+		{Path: "repo/a/b/c", Type: "folder", Created: old, Modified: recent},
+		{Path: "repo/a/d", Type: "folder", Created: old, Modified: old},
+		{Path: "repo/e", Type: "folder", Created: recent, Modified: recent},
+		{Path: "repo/e/f", Type: "folder", Created: old, Modified: old},
+	}
+	rules := &folderRules{modifiedBefore: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)}
+	assert.Equal(t, []string{"repo/a/d", "repo/e/f"}, runFilterEmptyFolders(t, items, rules))
+}
+
+// Runs filterEmptyFolders on the sorted items, and returns the paths of the empty folders found, sorted.
+func runFilterEmptyFolders(t *testing.T, items []clientrtutils.ResultItem, rules *folderRules) []string {
+	resultsWriter, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	for _, item := range items {
+		resultsWriter.Write(item)
+	}
+	assert.NoError(t, resultsWriter.Close())
+	resultsReader := content.NewContentReader(resultsWriter.GetFilePath(), content.DefaultKey)
+	defer func() {
+		assert.NoError(t, resultsReader.Close())
+	}()
+	sortedResultsReader, err := content.SortContentReaderByCalculatedKey(resultsReader, getTreeSortKey, true)
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, sortedResultsReader.Close())
+	}()
+
+	emptyFoldersWriter, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	count, err := filterEmptyFolders(sortedResultsReader, emptyFoldersWriter, rules)
+	assert.NoError(t, err)
+	assert.NoError(t, emptyFoldersWriter.Close())
+	emptyFoldersReader := content.NewContentReader(emptyFoldersWriter.GetFilePath(), content.DefaultKey)
+	defer func() {
+		assert.NoError(t, emptyFoldersReader.Close())
+	}()
+
+	emptyFolders := []string{}
+	for item := new(clientrtutils.ResultItem); emptyFoldersReader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		emptyFolders = append(emptyFolders, item.Path)
+	}
+	assert.NoError(t, emptyFoldersReader.GetError())
+	assert.Equal(t, len(items), count.scanned)
+	assert.Equal(t, count.found, len(emptyFolders))
+	sort.Strings(emptyFolders)
+	return emptyFolders
+}
+
+func TestFilterEmptyFoldersIgnoreFiles(t *testing.T) {
+	items := []clientrtutils.ResultItem{
+		{Path: "repo/org", Type: "folder"},
+		{Path: "repo/org/maven-metadata.xml", Type: "file"},
+		{Path: "repo/org/lib", Type: "folder"},
+		{Path: "repo/org/lib/maven-metadata.xml", Type: "file"},
+		{Path: "repo/org/lib/maven-metadata.xml.md5", Type: "file"},
+		{Path: "repo/org/lib/1.0", Type: "folder"},
+		{Path: "repo/org/lib/1.0/.DS_Store", Type: "file"},
+		{Path: "repo/org/app", Type: "folder"},
+		{Path: "repo/org/app/maven-metadata.xml", Type: "file"},
+		{Path: "repo/org/app/1.0", Type: "folder"},
+		{Path: "repo/org/app/1.0/app-1.0.jar", Type: "file"},
+	}
+	rules := &folderRules{ignoreFiles: []string{"maven-metadata.xml*", ".DS_Store"}}
+	assert.Equal(t, []string{"repo/org/lib"}, runFilterEmptyFolders(t, items, rules))
+	assert.Empty(t, runFilterEmptyFolders(t, items, new(folderRules)))
+}
+
+func getTestExclusionItems() []clientrtutils.ResultItem {
+	return []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder"},
+		{Path: "repo/a/b", Type: "folder"},
+		// A folder holding an exclude property is kept, and so are its ancestors.
+		{Path: "repo/a/b/c", Type: "folder", Properties: []clientrtutils.Property{{Key: "keep", Value: "true"}}},
+		{Path: "repo/a/d", Type: "folder", Properties: []clientrtutils.Property{{Key: "keep", Value: "false"}}},
+		{Path: "repo/e", Type: "folder"},
+		// A folder matching an exclude pattern is kept.
+		{Path: "repo/e/placeholders", Type: "folder"},
+		{Path: "repo/e/placeholders/x", Type: "folder"},
+		{Path: "repo/e/f", Type: "folder"},
+		{Path: "repo/g", Type: "folder"},
+	}
+}
+
+func getTestExclusionRules() *folderRules {
+	return &folderRules{
+		excludePatterns: []*regexp.Regexp{regexp.MustCompile("^.*/placeholders$")},
+		excludeProps:    map[string][]string{"keep": {"true"}},
+	}
+}
+
+func TestFilterEmptyFoldersExclusions(t *testing.T) {
+	assert.Equal(t, []string{"repo/a/d", "repo/e/f", "repo/e/placeholders/x", "repo/g"}, runFilterEmptyFolders(t, getTestExclusionItems(), getTestExclusionRules()))
+}
+
+func TestSearchResultItemToResultItem(t *testing.T) {
+	record := &searchResultItem{
+		ResultItem: clientrtutils.ResultItem{Path: "repo/a", Type: "folder"},
+		Props:      map[string][]string{"keep": {"true"}, "owner": {"ci", "qa"}},
+	}
+	expected := &clientrtutils.ResultItem{Path: "repo/a", Type: "folder", Properties: []clientrtutils.Property{
+		{Key: "keep", Value: "true"},
+		{Key: "owner", Value: "ci"},
+		{Key: "owner", Value: "qa"},
+	}}
+	assert.Equal(t, expected, record.toResultItem())
+}
+
+func TestFilterEmptyFoldersDepth(t *testing.T) {
+	items := []clientrtutils.ResultItem{
+		{Path: "repo/org", Type: "folder"},
+		{Path: "repo/org/lib", Type: "folder"},
+		{Path: "repo/org/lib/1.0", Type: "folder"},
+		{Path: "repo/org/app", Type: "folder"},
+		{Path: "repo/org/app/1.0", Type: "folder"},
+		{Path: "repo/org/app/1.0/app-1.0.jar", Type: "file"},
+		{Path: "repo/org/app/2.0", Type: "folder"},
+		{Path: "repo/org/app/2.0/x", Type: "folder"},
+		{Path: "repo/com", Type: "folder"},
+	}
+	var rules = []struct {
+		rules    *folderRules
+		expected []string
+	}{
+		{&folderRules{}, []string{"repo/com", "repo/org/app/2.0", "repo/org/lib"}},
+		// The top level folders are kept.
+		{&folderRules{minDepth: 2}, []string{"repo/org/app/2.0", "repo/org/lib"}},
+		{&folderRules{minDepth: 3}, []string{"repo/org/app/2.0", "repo/org/lib/1.0"}},
+		// Deep folders are deleted only together with an empty ancestor.
+		{&folderRules{maxDepth: 2}, []string{"repo/com", "repo/org/lib"}},
+		{&folderRules{maxDepth: 1}, []string{"repo/com"}},
+		{&folderRules{minDepth: 2, maxDepth: 2}, []string{"repo/org/lib"}},
+	}
+	for _, v := range rules {
+		assert.Equal(t, v.expected, runFilterEmptyFolders(t, items, v.rules.forRoot("repo/")), "rules: %+v", v.rules)
+	}
+}
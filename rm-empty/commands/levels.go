@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	searchStrategy     = "search"
+	levelsStrategy     = "levels"
+	defaultScanThreads = 3
+	// The maximum number of items returned by each folder listing query.
+	levelsPageSize = 10000
+)
+
+// Finds the empty folders under a path by walking its folder hierarchy level by level.
+// Each folder is listed separately with paged AQL queries, so the listing of the whole path is never held in memory
+// or on disk. The empty folders are written as soon as it is known that their parent folder must be kept.
+type levelScanner struct {
+	// Runs an AQL query and returns a reader of the ResultItems found.
+	execAql  func(aqlQuery string) (*content.ContentReader, error)
+	rules    *folderRules
+	writer   *content.ContentWriter
+	pageSize int
+	// Each token allows scanning one more folder in a new goroutine.
+	workerTokens chan struct{}
+	totalScanned atomic.Int64
+	totalFound   atomic.Int64
+}
+
+func newLevelScanner(execAql func(aqlQuery string) (*content.ContentReader, error), rules *folderRules, threads int, writer *content.ContentWriter) *levelScanner {
+	return &levelScanner{
+		execAql:  execAql,
+		rules:    rules,
+		writer:   writer,
+		pageSize: levelsPageSize,
+		// The scan of the root folder runs in the calling goroutine, so it doesn't need a token.
+		workerTokens: make(chan struct{}, threads-1),
+	}
+}
+
+// Finds the empty folders under the path by walking its folder hierarchy level by level, and writes them into the emptyFoldersWriter.
+func scanLevels(backend rmEmptyBackend, path string, rules *folderRules, threads int, emptyFoldersWriter *content.ContentWriter) (count scanCount, err error) {
+	log.Info("Scanning the folders under", path, "level by level")
+	scanner := newLevelScanner(backend.execAql, rules, threads, emptyFoldersWriter)
+	return scanner.scan(path)
+}
+
+// Scans the folder hierarchy under the path. The path itself is never written as an empty folder.
+func (s *levelScanner) scan(path string) (count scanCount, err error) {
+	root := &clientrtutils.ResultItem{Path: strings.Trim(path, "/"), Type: "folder"}
+	_, err = s.scanFolder(root, true)
+	return scanCount{scanned: int(s.totalScanned.Load()), found: int(s.totalFound.Load())}, err
+}
+
+// Lists the folder and scans its subfolders recursively.
+// Returns true if the entire subtree of the folder is empty and it may be deleted. In that case, the folder is left
+// for its parent to write. Otherwise, the empty subfolders of the folder are written.
+func (s *levelScanner) scanFolder(folder *clientrtutils.ResultItem, root bool) (empty bool, err error) {
+	subfolders, hasFiles, err := s.listFolder(folder.Path)
+	if err != nil {
+		return false, err
+	}
+	if s.rules.needsProperties() && len(subfolders) > 0 {
+		if err = s.loadExcludeProperties(folder.Path, subfolders); err != nil {
+			return false, err
+		}
+	}
+	keep := root || hasFiles || s.rules.keepFolder(folder)
+
+	// Scan the subfolders. When a worker token is available, the subfolder is scanned in a new goroutine.
+	emptySubfolders := make([]bool, len(subfolders))
+	errs := make([]error, len(subfolders))
+	var wg sync.WaitGroup
+	for i, subfolder := range subfolders {
+		select {
+		case s.workerTokens <- struct{}{}:
+			wg.Add(1)
+			go func(i int, subfolder *clientrtutils.ResultItem) {
+				defer func() {
+					<-s.workerTokens
+					wg.Done()
+				}()
+				emptySubfolders[i], errs[i] = s.scanFolder(subfolder, false)
+			}(i, subfolder)
+		default:
+			emptySubfolders[i], errs[i] = s.scanFolder(subfolder, false)
+		}
+	}
+	wg.Wait()
+	if err = errors.Join(errs...); err != nil {
+		return false, err
+	}
+
+	for _, emptySubfolder := range emptySubfolders {
+		keep = keep || !emptySubfolder
+	}
+	if !keep {
+		return true, nil
+	}
+	// The folder is kept, so its empty subfolders are the highest in their subtrees.
+	for i, subfolder := range subfolders {
+		if emptySubfolders[i] && s.rules.deletable(subfolder) {
+			s.writer.Write(subfolder)
+			s.totalFound.Add(1)
+		}
+	}
+	return false, nil
+}
+
+// Lists the direct children of the folder page by page.
+// Returns the subfolders of the folder, and whether it holds files which are not ignored by the rules.
+func (s *levelScanner) listFolder(folderPath string) (subfolders []*clientrtutils.ResultItem, hasFiles bool, err error) {
+	repo, pathInRepo := splitRepoPath(folderPath)
+	for offset := 0; ; offset += s.pageSize {
+		var pageLength int
+		if pageLength, err = s.listPage(buildListFolderAQL(repo, pathInRepo, offset, s.pageSize), func(item *clientrtutils.ResultItem) {
+			s.totalScanned.Add(1)
+			if item.Type == "folder" {
+				subfolders = append(subfolders, item)
+			} else if !s.rules.ignoreFile(item) {
+				hasFiles = true
+			}
+		}); err != nil || pageLength < s.pageSize {
+			return
+		}
+	}
+}
+
+// Sets the properties of the subfolders holding one of the exclude properties of the rules.
+// Artifactory doesn't support including properties in sorted queries, so they are fetched by a separate query.
+func (s *levelScanner) loadExcludeProperties(folderPath string, subfolders []*clientrtutils.ResultItem) error {
+	subfoldersByName := make(map[string]*clientrtutils.ResultItem, len(subfolders))
+	for _, subfolder := range subfolders {
+		subfoldersByName[path.Base(subfolder.Path)] = subfolder
+	}
+	repo, pathInRepo := splitRepoPath(folderPath)
+	_, err := s.listPage(buildPropertiesAQL(repo, pathInRepo, s.rules.excludeProps), func(item *clientrtutils.ResultItem) {
+		if subfolder, ok := subfoldersByName[path.Base(item.Path)]; ok {
+			subfolder.Properties = append(subfolder.Properties, item.Properties...)
+		}
+	})
+	return err
+}
+
+// Runs a single folder listing query, and calls handleItem for each of the items found.
+// The items are converted to hold their full path in Artifactory in their Path, like the search results.
+// Returns the number of items in the page.
+func (s *levelScanner) listPage(aqlQuery string, handleItem func(item *clientrtutils.ResultItem)) (pageLength int, err error) {
+	reader, err := s.execAql(aqlQuery)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := reader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+	for item := new(clientrtutils.ResultItem); reader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		pageLength++
+		// The root folder of a repository is returned when listing the root of the repository.
+		if item.Name == "." {
+			continue
+		}
+		handleItem(&clientrtutils.ResultItem{
+			Path:     strings.TrimSuffix(item.GetItemRelativePath(), "/"),
+			Type:     item.Type,
+			Size:     item.Size,
+			Created:  item.Created,
+			Modified: item.Modified,
+			// Only returned by the properties queries.
+			Properties: item.Properties,
+		})
+	}
+	return pageLength, reader.GetError()
+}
+
+// Splits a path in Artifactory into the repository and the path inside it. The root of a repository is ".".
+func splitRepoPath(fullPath string) (repo, pathInRepo string) {
+	repo, pathInRepo, _ = strings.Cut(strings.Trim(fullPath, "/"), "/")
+	if pathInRepo == "" {
+		pathInRepo = "."
+	}
+	return
+}
+
+// Builds a query for the subfolders of a folder holding one of the properties.
+func buildPropertiesAQL(repo, pathInRepo string, props map[string][]string) string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var criteria []string
+	for _, key := range keys {
+		for _, value := range props[key] {
+			criteria = append(criteria, fmt.Sprintf(`{%q:%q}`, "@"+key, value))
+		}
+	}
+	return fmt.Sprintf(`items.find({"repo":%q,"path":%q,"type":"folder","$or":[%s]}).include("repo","path","name","property")`,
+		repo, pathInRepo, strings.Join(criteria, ","))
+}
+
+func buildListFolderAQL(repo, pathInRepo string, offset, limit int) string {
+	return fmt.Sprintf(`items.find({"repo":%q,"path":%q,"type":"any"}).include("repo","path","name","type","size","created","modified").sort({"$asc":["name"]}).offset(%d).limit(%d)`,
+		repo, pathInRepo, offset, limit)
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRepoPath(t *testing.T) {
+	var paths = []struct {
+		fullPath   string
+		repo       string
+		pathInRepo string
+	}{
+		{"repo", "repo", "."},
+		{"repo/", "repo", "."},
+		{"repo/a", "repo", "a"},
+		{"repo/a/b/", "repo", "a/b"},
+	}
+	for _, v := range paths {
+		repo, pathInRepo := splitRepoPath(v.fullPath)
+		assert.Equal(t, v.repo, repo, "splitRepoPath(%q)", v.fullPath)
+		assert.Equal(t, v.pathInRepo, pathInRepo, "splitRepoPath(%q)", v.fullPath)
+	}
+}
+
+func TestBuildPropertiesAQL(t *testing.T) {
+	expected := `items.find({"repo":"repo","path":".","type":"folder","$or":[{"@keep":"true"},{"@owner":"ci"},{"@owner":"qa"}]}).include("repo","path","name","property")`
+	assert.Equal(t, expected, buildPropertiesAQL("repo", ".", map[string][]string{"owner": {"ci", "qa"}, "keep": {"true"}}))
+}
+
+func TestBuildListFolderAQL(t *testing.T) {
+	expected := `items.find({"repo":"repo","path":"a/b","type":"any"}).include("repo","path","name","type","size","created","modified").sort({"$asc":["name"]}).offset(20).limit(10)`
+	assert.Equal(t, expected, buildListFolderAQL("repo", "a/b", 20, 10))
+}
+
+var scanLevelsProvider = []struct {
+	name  string
+	root  string
+	items []clientrtutils.ResultItem
+	rules *folderRules
+}{
+	{"leaves", "a", getTestItems(), new(folderRules)},
+	{"trees", "repo/", []clientrtutils.ResultItem{
+		{Path: "repo/x", Type: "folder"},
+		{Path: "repo/x/y", Type: "folder"},
+		{Path: "repo/x/y/z", Type: "folder"},
+		{Path: "repo/x/w", Type: "folder"},
+		{Path: "repo/a", Type: "folder"},
+		{Path: "repo/a/b", Type: "folder"},
+		{Path: "repo/a/b/c", Type: "folder"},
+		{Path: "repo/a/d", Type: "folder"},
+		{Path: "repo/a/d/e", Type: "folder"},
+		{Path: "repo/a/d/e/file.txt", Type: "file"},
+		{Path: "repo/a/d/f", Type: "folder"},
+		{Path: "repo/a/d/g", Type: "folder"},
+		{Path: "repo/a/d/h", Type: "folder"},
+	}, new(folderRules)},
+	{"similar names", "org", getTestSimilarNameItems(), new(folderRules)},
+	{"ignored files", "repo", []clientrtutils.ResultItem{
+		{Path: "repo/org", Type: "folder"},
+		{Path: "repo/org/maven-metadata.xml", Type: "file"},
+		{Path: "repo/org/lib", Type: "folder"},
+		{Path: "repo/org/lib/maven-metadata.xml", Type: "file"},
+		{Path: "repo/org/lib/1.0", Type: "folder"},
+		{Path: "repo/org/app", Type: "folder"},
+		{Path: "repo/org/app/1.0", Type: "folder"},
+		{Path: "repo/org/app/1.0/app-1.0.jar", Type: "file"},
+	}, &folderRules{ignoreFiles: []string{"maven-metadata.xml*"}}},
+	{"min age", "repo", []clientrtutils.ResultItem{
+		{Path: "repo/a", Type: "folder", Created: "2020-01-01T00:00:00.000Z", Modified: "2020-01-01T00:00:00.000Z"},
+		{Path: "repo/a/b", Type: "folder", Created: "2020-01-01T00:00:00.000Z", Modified: "2020-06-01T00:00:00.000Z"},
+		{Path: "repo/a/c", Type: "folder", Created: "2020-01-01T00:00:00.000Z", Modified: "2020-01-01T00:00:00.000Z"},
+	}, &folderRules{modifiedBefore: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)}},
+	{"exclusions", "repo", getTestExclusionItems(), getTestExclusionRules()},
+	{"depth", "repo/a", []clientrtutils.ResultItem{
+		{Path: "repo/a/org", Type: "folder"},
+		{Path: "repo/a/org/lib", Type: "folder"},
+		{Path: "repo/a/org/lib/1.0", Type: "folder"},
+		{Path: "repo/a/org/app", Type: "folder"},
+		{Path: "repo/a/org/app/1.0", Type: "folder"},
+		{Path: "repo/a/org/app/1.0/app-1.0.jar", Type: "file"},
+		{Path: "repo/a/com", Type: "folder"},
+		{Path: "repo/a/com/x", Type: "folder"},
+		{Path: "repo/a/com/x/y", Type: "folder"},
+	}, (&folderRules{minDepth: 2, maxDepth: 2}).forRoot("repo/a")},
+}
+
+// The levels strategy must find the same empty folders as the search strategy.
+func TestScanLevels(t *testing.T) {
+	for _, sample := range scanLevelsProvider {
+		for _, threads := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s with %d threads", sample.name, threads), func(t *testing.T) {
+				expected := runFilterEmptyFolders(t, sample.items, sample.rules)
+				assert.Equal(t, expected, runScanLevels(t, sample.root, sample.items, sample.rules, threads))
+			})
+		}
+	}
+}
+
+func TestScanLevelsError(t *testing.T) {
+	execAql := func(aqlQuery string) (*content.ContentReader, error) {
+		return nil, errors.New("listing failed")
+	}
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	_, err = newLevelScanner(execAql, new(folderRules), 2, writer).scan("repo")
+	assert.EqualError(t, err, "listing failed")
+	assert.NoError(t, writer.Close())
+}
+
+// Runs the levels strategy on a fake listing of the items, and returns the paths of the empty folders found, sorted.
+func runScanLevels(t *testing.T, root string, items []clientrtutils.ResultItem, rules *folderRules, threads int) []string {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	// Use small pages, to list most of the folders in multiple pages.
+	pageSize := 2
+	scanner := newLevelScanner(newFakeListing(t, root, items, pageSize, rules.excludeProps), rules, threads, writer)
+	scanner.pageSize = pageSize
+	count, err := scanner.scan(root)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	reader := content.NewContentReader(writer.GetFilePath(), content.DefaultKey)
+	defer func() {
+		assert.NoError(t, reader.Close())
+	}()
+
+	emptyFolders := []string{}
+	for item := new(clientrtutils.ResultItem); reader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		emptyFolders = append(emptyFolders, item.Path)
+	}
+	assert.NoError(t, reader.GetError())
+	assert.Equal(t, len(items), count.scanned)
+	assert.Equal(t, count.found, len(emptyFolders))
+	sort.Strings(emptyFolders)
+	return emptyFolders
+}
+
+// Returns an execAql function which answers the folder listing queries of the scanner, in pages of pageSize items.
+// If props are provided, the queries for the subfolders holding these properties are answered as well.
+// The items hold their full path in their Path, like the search results.
+func newFakeListing(t *testing.T, root string, items []clientrtutils.ResultItem, pageSize int, props map[string][]string) func(aqlQuery string) (*content.ContentReader, error) {
+	children := map[string][]clientrtutils.ResultItem{path.Clean(root): nil}
+	for _, item := range items {
+		parent, name := path.Split(item.Path)
+		parent = path.Clean(parent)
+		repo, pathInRepo := splitRepoPath(parent)
+		children[parent] = append(children[parent], clientrtutils.ResultItem{
+			Repo: repo, Path: pathInRepo, Name: name, Type: item.Type, Created: item.Created, Modified: item.Modified, Properties: item.Properties,
+		})
+		if item.Type == "folder" {
+			if _, exists := children[item.Path]; !exists {
+				children[item.Path] = nil
+			}
+		}
+	}
+
+	pages := make(map[string][]clientrtutils.ResultItem)
+	for folder, folderChildren := range children {
+		sort.Slice(folderChildren, func(i, j int) bool {
+			return folderChildren[i].Name < folderChildren[j].Name
+		})
+		repo, pathInRepo := splitRepoPath(folder)
+		for offset := 0; offset == 0 || offset < len(folderChildren); offset += pageSize {
+			end := min(offset+pageSize, len(folderChildren))
+			pages[buildListFolderAQL(repo, pathInRepo, offset, pageSize)] = folderChildren[offset:end]
+		}
+		if len(folderChildren)%pageSize == 0 && len(folderChildren) > 0 {
+			pages[buildListFolderAQL(repo, pathInRepo, len(folderChildren), pageSize)] = nil
+		}
+		if props != nil {
+			var withProps []clientrtutils.ResultItem
+			for _, child := range folderChildren {
+				if child.Type == "folder" && (&folderRules{excludeProps: props}).isProtected(&child) {
+					withProps = append(withProps, child)
+				}
+			}
+			pages[buildPropertiesAQL(repo, pathInRepo, props)] = withProps
+		}
+	}
+
+	return func(aqlQuery string) (*content.ContentReader, error) {
+		page, ok := pages[aqlQuery]
+		if !ok {
+			t.Errorf("unexpected AQL query: %s", aqlQuery)
+			return nil, errors.New("unexpected AQL query")
+		}
+		writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			writer.Write(item)
+		}
+		if err = writer.Close(); err != nil {
+			return nil, err
+		}
+		return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+	}
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+// A record of the folders deleted by a run, which allows restoring them.
+type manifest struct {
+	ArtifactoryUrl string `json:"artifactoryUrl"`
+	Created        string `json:"created"`
+	// The deleted folders and all their subfolders, which were deleted together with them, sorted by their paths.
+	Folders []manifestFolder `json:"folders"`
+}
+
+type manifestFolder struct {
+	Path       string              `json:"path"`
+	Properties map[string][]string `json:"properties,omitempty"`
+}
+
+// Records the folders deleted, with the subfolders deleted together with them and their properties.
+type manifestRecorder struct {
+	// Runs an AQL query and returns a reader of the ResultItems found.
+	execAql func(aqlQuery string) (*content.ContentReader, error)
+	mutex   sync.Mutex
+	folders []manifestFolder
+}
+
+func newManifestRecorder(execAql func(aqlQuery string) (*content.ContentReader, error)) *manifestRecorder {
+	return &manifestRecorder{execAql: execAql}
+}
+
+// Wraps the deleteItemFunc, so that the subtree of each folder is recorded before it is deleted.
+// A folder whose subtree can't be recorded is not deleted.
+func (m *manifestRecorder) wrap(deleteFolder deleteItemFunc) deleteItemFunc {
+	return func(folder *clientrtutils.ResultItem) error {
+		subtree, err := m.listSubtree(strings.Trim(folder.Path, "/"))
+		if err != nil {
+			return fmt.Errorf("failed recording the folder in the manifest: %w", err)
+		}
+		if err = deleteFolder(folder); err != nil {
+			return err
+		}
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		m.folders = append(m.folders, subtree...)
+		return nil
+	}
+}
+
+// Returns the folder and all its subfolders, with their properties.
+func (m *manifestRecorder) listSubtree(folderPath string) (subtree []manifestFolder, err error) {
+	reader, err := m.execAql(buildSubtreeFoldersAQL(folderPath))
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := reader.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+	for item := new(clientrtutils.ResultItem); reader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		folder := manifestFolder{Path: strings.TrimSuffix(item.GetItemRelativePath(), "/")}
+		for _, property := range item.Properties {
+			if folder.Properties == nil {
+				folder.Properties = make(map[string][]string)
+			}
+			folder.Properties[property.Key] = append(folder.Properties[property.Key], property.Value)
+		}
+		subtree = append(subtree, folder)
+	}
+	return subtree, reader.GetError()
+}
+
+// Returns the manifest of the folders recorded.
+func (m *manifestRecorder) manifest(artifactoryUrl string, now time.Time) *manifest {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	folders := append([]manifestFolder{}, m.folders...)
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].Path+"/" < folders[j].Path+"/"
+	})
+	return &manifest{ArtifactoryUrl: artifactoryUrl, Created: now.Format(time.RFC3339), Folders: folders}
+}
+
+// Builds a query for a folder and all the folders under it, with their properties.
+func buildSubtreeFoldersAQL(folderPath string) string {
+	repo, pathInRepo := splitRepoPath(folderPath)
+	parent, name := path.Split(pathInRepo)
+	if parent = strings.TrimSuffix(parent, "/"); parent == "" {
+		parent = "."
+	}
+	return fmt.Sprintf(`items.find({"repo":%q,"type":"folder","$or":[{"path":%q,"name":%q},{"path":%q},{"path":{"$match":%q}}]}).include("repo","path","name","property")`,
+		repo, parent, name, pathInRepo, pathInRepo+"/*")
+}
+
+func saveManifest(m *manifest, manifestPath string) error {
+	output, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, output, 0600)
+}
+
+func loadManifest(manifestPath string) (*manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	m := new(manifest)
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed parsing the manifest %s: %w", manifestPath, err)
+	}
+	return m, nil
+}
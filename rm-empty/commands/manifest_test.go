@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSubtreeFoldersAQL(t *testing.T) {
+	expected := `items.find({"repo":"repo","type":"folder","$or":[{"path":"a","name":"b"},{"path":"a/b"},{"path":{"$match":"a/b/*"}}]}).include("repo","path","name","property")`
+	assert.Equal(t, expected, buildSubtreeFoldersAQL("repo/a/b/"))
+	expected = `items.find({"repo":"repo","type":"folder","$or":[{"path":".","name":"a"},{"path":"a"},{"path":{"$match":"a/*"}}]}).include("repo","path","name","property")`
+	assert.Equal(t, expected, buildSubtreeFoldersAQL("repo/a"))
+}
+
+func TestManifestRecorder(t *testing.T) {
+	subtrees := map[string][]clientrtutils.ResultItem{
+		buildSubtreeFoldersAQL("repo/b"): {
+			{Repo: "repo", Path: ".", Name: "b", Type: "folder", Properties: []clientrtutils.Property{{Key: "owner", Value: "ci"}, {Key: "owner", Value: "qa"}}},
+			{Repo: "repo", Path: "b", Name: "c", Type: "folder"},
+		},
+		buildSubtreeFoldersAQL("repo/a"): {
+			{Repo: "repo", Path: ".", Name: "a", Type: "folder", Properties: []clientrtutils.Property{{Key: "keep", Value: "false"}}},
+		},
+	}
+	execAql := func(aqlQuery string) (*content.ContentReader, error) {
+		items, ok := subtrees[aqlQuery]
+		if !ok {
+			return nil, errors.New("unexpected AQL query: " + aqlQuery)
+		}
+		writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			writer.Write(item)
+		}
+		if err = writer.Close(); err != nil {
+			return nil, err
+		}
+		return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+	}
+
+	recorder := newManifestRecorder(execAql)
+	var deleted []string
+	deleteFolder := recorder.wrap(func(folder *clientrtutils.ResultItem) error {
+		if folder.Path == "repo/d" {
+			return errors.New("forbidden")
+		}
+		deleted = append(deleted, folder.Path)
+		return nil
+	})
+	assert.NoError(t, deleteFolder(&clientrtutils.ResultItem{Path: "repo/b", Type: "folder"}))
+	assert.NoError(t, deleteFolder(&clientrtutils.ResultItem{Path: "repo/a", Type: "folder"}))
+	// A folder which can't be recorded is not deleted.
+	assert.Error(t, deleteFolder(&clientrtutils.ResultItem{Path: "repo/c", Type: "folder"}))
+	assert.Equal(t, []string{"repo/b", "repo/a"}, deleted)
+
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	expected := &manifest{
+		ArtifactoryUrl: "http://localhost:8081/artifactory/",
+		Created:        "2021-01-01T12:00:00Z",
+		Folders: []manifestFolder{
+			{Path: "repo/a", Properties: map[string][]string{"keep": {"false"}}},
+			{Path: "repo/b", Properties: map[string][]string{"owner": {"ci", "qa"}}},
+			{Path: "repo/b/c"},
+		},
+	}
+	assert.Equal(t, expected, recorder.manifest("http://localhost:8081/artifactory/", now))
+}
+
+func TestSaveAndLoadManifest(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	expected := &manifest{
+		ArtifactoryUrl: "http://localhost:8081/artifactory/",
+		Created:        "2021-01-01T12:00:00Z",
+		Folders:        []manifestFolder{{Path: "repo/a", Properties: map[string][]string{"keep": {"false"}}}, {Path: "repo/a/b"}},
+	}
+	assert.NoError(t, saveManifest(expected, manifestPath))
+	actual, err := loadManifest(manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = loadManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+	csvFormat  = "csv"
+)
+
+// Reads the format flag of a command with a dry run output, which requires the dry-run flag unless it's text.
+func getFormatFlagValue(c *components.Context, dryRun bool) (string, error) {
+	format := strings.ToLower(c.GetStringFlagValue("format"))
+	if format != textFormat && format != jsonFormat && format != csvFormat {
+		return "", errors.New("wrong format. Expected: text, json or csv. Received: " + format)
+	}
+	if format != textFormat && !dryRun {
+		return "", errors.New("the format flag can only be used together with the dry-run flag")
+	}
+	return format, nil
+}
+
+// An empty folder or file, as printed by a dry run.
+type itemRecord struct {
+	Path string `json:"path"`
+	// The size of a file. Nil for folders.
+	Size     *int64 `json:"size,omitempty"`
+	Created  string `json:"created,omitempty"`
+	Modified string `json:"modified,omitempty"`
+}
+
+// Prints the items in the reader to out, in the provided format. If withSize is true, the size of each item is
+// printed as well.
+// The items are streamed one by one, so that long lists are not held in memory.
+func printItems(reader *content.ContentReader, format string, withSize bool, out io.Writer) (err error) {
+	var csvWriter *csv.Writer
+	switch format {
+	case jsonFormat:
+		if _, err = fmt.Fprint(out, "["); err != nil {
+			return
+		}
+	case csvFormat:
+		csvWriter = csv.NewWriter(out)
+		header := []string{"path", "created", "modified"}
+		if withSize {
+			header = []string{"path", "size", "created", "modified"}
+		}
+		if err = csvWriter.Write(header); err != nil {
+			return
+		}
+	}
+
+	first := true
+	for item := new(clientrtutils.ResultItem); reader.NextRecord(item) == nil; item = new(clientrtutils.ResultItem) {
+		record := itemRecord{Path: item.Path, Created: item.Created, Modified: item.Modified}
+		if withSize {
+			record.Size = &item.Size
+		}
+		switch format {
+		case jsonFormat:
+			var recordJson []byte
+			if recordJson, err = json.Marshal(record); err != nil {
+				return
+			}
+			separator := ","
+			if first {
+				separator = ""
+			}
+			_, err = fmt.Fprintf(out, "%s\n  %s", separator, recordJson)
+		case csvFormat:
+			row := []string{record.Path, record.Created, record.Modified}
+			if withSize {
+				row = []string{record.Path, strconv.FormatInt(item.Size, 10), record.Created, record.Modified}
+			}
+			err = csvWriter.Write(row)
+		default:
+			if withSize {
+				_, err = fmt.Fprintf(out, "%s (%d bytes)\n", record.Path, item.Size)
+			} else {
+				_, err = fmt.Fprintln(out, record.Path)
+			}
+		}
+		if err != nil {
+			return
+		}
+		first = false
+	}
+	if err = reader.GetError(); err != nil {
+		return
+	}
+
+	switch format {
+	case jsonFormat:
+		if first {
+			_, err = fmt.Fprintln(out, "]")
+		} else {
+			_, err = fmt.Fprintln(out, "\n]")
+		}
+	case csvFormat:
+		csvWriter.Flush()
+		err = csvWriter.Error()
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+var printItemsProvider = []struct {
+	format   string
+	expected string
+}{
+	{textFormat, "repo/a\nrepo/b,c\n"},
+	{csvFormat, "path,created,modified\nrepo/a,2021-01-01T00:00:00.000Z,2021-01-02T00:00:00.000Z\n\"repo/b,c\",,\n"},
+	{jsonFormat, "[\n  {\"path\":\"repo/a\",\"created\":\"2021-01-01T00:00:00.000Z\",\"modified\":\"2021-01-02T00:00:00.000Z\"},\n  {\"path\":\"repo/b,c\"}\n]\n"},
+}
+
+func TestPrintEmptyFolders(t *testing.T) {
+	for _, sample := range printItemsProvider {
+		t.Run(sample.format, func(t *testing.T) {
+			reader := createTestReader(t, []clientrtutils.ResultItem{
+				{Path: "repo/a", Type: "folder", Created: "2021-01-01T00:00:00.000Z", Modified: "2021-01-02T00:00:00.000Z"},
+				{Path: "repo/b,c", Type: "folder"},
+			})
+			out := new(bytes.Buffer)
+			assert.NoError(t, printItems(reader, sample.format, false, out))
+			assert.Equal(t, sample.expected, out.String())
+		})
+	}
+}
+
+var printItemsWithSizeProvider = []struct {
+	format   string
+	expected string
+}{
+	{textFormat, "repo/a.jar (0 bytes)\nrepo/a.jar.sha1 (40 bytes)\n"},
+	{csvFormat, "path,size,created,modified\nrepo/a.jar,0,2021-01-01T00:00:00.000Z,\nrepo/a.jar.sha1,40,,\n"},
+	{jsonFormat, "[\n  {\"path\":\"repo/a.jar\",\"size\":0,\"created\":\"2021-01-01T00:00:00.000Z\"},\n  {\"path\":\"repo/a.jar.sha1\",\"size\":40}\n]\n"},
+}
+
+func TestPrintFilesWithSize(t *testing.T) {
+	for _, sample := range printItemsWithSizeProvider {
+		t.Run(sample.format, func(t *testing.T) {
+			reader := createTestReader(t, []clientrtutils.ResultItem{
+				{Path: "repo/a.jar", Type: "file", Created: "2021-01-01T00:00:00.000Z"},
+				{Path: "repo/a.jar.sha1", Type: "file", Size: 40},
+			})
+			out := new(bytes.Buffer)
+			assert.NoError(t, printItems(reader, sample.format, true, out))
+			assert.Equal(t, sample.expected, out.String())
+		})
+	}
+}
+
+func TestPrintNoEmptyFoldersJson(t *testing.T) {
+	reader := createTestReader(t, nil)
+	out := new(bytes.Buffer)
+	assert.NoError(t, printItems(reader, jsonFormat, false, out))
+	var records []itemRecord
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	assert.Empty(t, records)
+}
+
+// Returns a reader of the provided items, which is closed when the test ends.
+func createTestReader(t *testing.T, items []clientrtutils.ResultItem) *content.ContentReader {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	assert.NoError(t, err)
+	for _, item := range items {
+		writer.Write(item)
+	}
+	assert.NoError(t, writer.Close())
+	reader := content.NewContentReader(writer.GetFilePath(), content.DefaultKey)
+	t.Cleanup(func() {
+		assert.NoError(t, reader.Close())
+	})
+	return reader
+}
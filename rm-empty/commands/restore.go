@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-client-go/artifactory"
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/auth"
+	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/errorutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+func GetRestoreCommand() components.Command {
+	return components.Command{
+		Name:        "restore",
+		Description: "Restore the folders recorded in a manifest saved by the folders command",
+		Aliases:     []string{"r"},
+		Arguments:   getRestoreArguments(),
+		Flags:       getRestoreFlags(),
+		EnvVars:     getEnvVars(),
+		Action:      restoreCmd,
+	}
+}
+
+func getRestoreArguments() []components.Argument {
+	return []components.Argument{
+		{
+			Name:        "manifest",
+			Description: "The path of a manifest file, saved by the folders command using the manifest flag",
+		},
+	}
+}
+
+func getRestoreFlags() []components.Flag {
+	return []components.Flag{
+		components.NewStringFlag("server-id", "Artifactory server ID configured using the config command"),
+		components.NewBoolFlag("from-trash", "Restore the folders from the trash can of Artifactory, instead of recreating them and their properties from the manifest"),
+	}
+}
+
+func restoreCmd(c *components.Context) error {
+	if len(c.Arguments) != 1 {
+		return errors.New("wrong number of arguments. Expected: 1, " + "Received: " + strconv.Itoa(len(c.Arguments)))
+	}
+	m, err := loadManifest(c.Arguments[0])
+	if err != nil {
+		return err
+	}
+	rtDetails, err := getRtDetails(c)
+	if err != nil {
+		return err
+	}
+	if m.ArtifactoryUrl != "" && clientutils.AddTrailingSlashIfNeeded(m.ArtifactoryUrl) != clientutils.AddTrailingSlashIfNeeded(rtDetails.ArtifactoryUrl) {
+		log.Warn("The manifest was saved for", m.ArtifactoryUrl+", but the folders are restored to", rtDetails.ArtifactoryUrl)
+	}
+	restorer, err := newArtifactoryRestorer(rtDetails)
+	if err != nil {
+		return err
+	}
+
+	restored, failures := restoreFolders(m, c.GetBoolFlagValue("from-trash"), restorer)
+	log.Info("Restored", restored, "folders.")
+	if len(failures) > 0 {
+		logFailures("Failed restoring", "folders", failures)
+		return fmt.Errorf("failed restoring %d folders", len(failures))
+	}
+	return nil
+}
+
+// Restores deleted folders in Artifactory.
+type folderRestorer interface {
+	// Creates the folder, if it doesn't exist.
+	createFolder(folderPath string) error
+	// Sets the properties on the folder only, without its subfolders.
+	setProperties(folderPath string, props map[string][]string) error
+	// Restores the folder and its entire subtree from the trash can.
+	restoreFromTrash(folderPath string) error
+}
+
+// Restores the folders of the manifest, and returns the number of folders restored and the failures.
+// From the trash can, only the highest folders are restored, since their subfolders are restored together with them.
+// Otherwise, each folder is recreated and its properties are reapplied. Parent folders are recreated before their
+// subfolders.
+func restoreFolders(m *manifest, fromTrash bool, restorer folderRestorer) (restored int, failures []itemFailure) {
+	if fromTrash {
+		paths := make([]string, 0, len(m.Folders))
+		for _, folder := range m.Folders {
+			paths = append(paths, folder.Path)
+		}
+		for _, folderPath := range removeNestedTargets(paths) {
+			if err := restorer.restoreFromTrash(folderPath); err != nil {
+				failures = append(failures, itemFailure{path: folderPath, err: err})
+				continue
+			}
+			log.Info("Restored", folderPath, "from the trash can")
+			restored++
+		}
+		return
+	}
+
+	// The folders are sorted by their paths in the manifest, so each folder comes after its parent.
+	for _, folder := range m.Folders {
+		err := restorer.createFolder(folder.Path)
+		if err == nil && len(folder.Properties) > 0 {
+			err = restorer.setProperties(folder.Path, folder.Properties)
+		}
+		if err != nil {
+			failures = append(failures, itemFailure{path: folder.Path, err: err})
+			continue
+		}
+		log.Info("Restored", folder.Path)
+		restored++
+	}
+	return
+}
+
+// A folderRestorer which restores the folders in an Artifactory server, using its REST API.
+type artifactoryRestorer struct {
+	serviceManager artifactory.ArtifactoryServicesManager
+	artDetails     auth.ServiceDetails
+}
+
+func newArtifactoryRestorer(rtDetails *config.ServerDetails) (*artifactoryRestorer, error) {
+	serviceManager, err := utils.CreateServiceManager(rtDetails, 3, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactoryRestorer{serviceManager: serviceManager, artDetails: serviceManager.GetConfig().GetServiceDetails()}, nil
+}
+
+func (r *artifactoryRestorer) createFolder(folderPath string) error {
+	return r.send(http.MethodPut, folderPath+"/", nil, "", http.StatusCreated)
+}
+
+func (r *artifactoryRestorer) setProperties(folderPath string, props map[string][]string) error {
+	properties := clientrtutils.NewProperties()
+	for key, values := range props {
+		for _, value := range values {
+			properties.AddProperty(key, value)
+		}
+	}
+	return r.send(http.MethodPut, "api/storage/"+folderPath, map[string]string{"recursive": "0"},
+		"&properties="+properties.ToEncodedString(true), http.StatusNoContent)
+}
+
+func (r *artifactoryRestorer) restoreFromTrash(folderPath string) error {
+	return r.send(http.MethodPost, "api/trash/restore/"+folderPath, map[string]string{"to": folderPath}, "", http.StatusOK, http.StatusAccepted)
+}
+
+// Sends a request to the path in Artifactory, and checks its response status.
+// The rawQuery is appended to the URL as is, after the encoded params.
+func (r *artifactoryRestorer) send(method, restPath string, params map[string]string, rawQuery string, expectedStatusCodes ...int) error {
+	if params == nil {
+		params = make(map[string]string)
+	}
+	url, err := clientutils.BuildUrl(r.artDetails.GetUrl(), restPath, params)
+	if err != nil {
+		return err
+	}
+	httpClientDetails := r.artDetails.CreateHttpClientDetails()
+	var resp *http.Response
+	var body []byte
+	if method == http.MethodPost {
+		resp, body, err = r.serviceManager.Client().SendPost(url+rawQuery, nil, &httpClientDetails)
+	} else {
+		resp, body, err = r.serviceManager.Client().SendPut(url+rawQuery, nil, &httpClientDetails)
+	}
+	if err != nil {
+		return err
+	}
+	return errorutils.CheckResponseStatusWithBody(resp, body, expectedStatusCodes...)
+}
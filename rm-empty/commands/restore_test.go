@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// A folderRestorer which records the calls made to it.
+type fakeRestorer struct {
+	calls []string
+	// Paths of folders that fail to be restored.
+	failingPaths map[string]bool
+}
+
+func (r *fakeRestorer) record(call, folderPath string) error {
+	r.calls = append(r.calls, call+" "+folderPath)
+	if r.failingPaths[folderPath] {
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+func (r *fakeRestorer) createFolder(folderPath string) error {
+	return r.record("create", folderPath)
+}
+
+func (r *fakeRestorer) setProperties(folderPath string, props map[string][]string) error {
+	return r.record("props", folderPath)
+}
+
+func (r *fakeRestorer) restoreFromTrash(folderPath string) error {
+	return r.record("trash", folderPath)
+}
+
+func getTestManifest() *manifest {
+	return &manifest{Folders: []manifestFolder{
+		{Path: "repo/a", Properties: map[string][]string{"keep": {"false"}}},
+		{Path: "repo/a/b"},
+		{Path: "repo/a/b/c", Properties: map[string][]string{"owner": {"ci"}}},
+		{Path: "repo/d"},
+	}}
+}
+
+func TestRestoreFolders(t *testing.T) {
+	restorer := &fakeRestorer{failingPaths: map[string]bool{"repo/d": true}}
+	restored, failures := restoreFolders(getTestManifest(), false, restorer)
+	assert.Equal(t, 3, restored)
+	assert.Equal(t, []itemFailure{{path: "repo/d", err: errors.New("forbidden")}}, failures)
+	assert.Equal(t, []string{"create repo/a", "props repo/a", "create repo/a/b", "create repo/a/b/c", "props repo/a/b/c", "create repo/d"}, restorer.calls)
+}
+
+func TestRestoreFoldersFromTrash(t *testing.T) {
+	restorer := &fakeRestorer{}
+	restored, failures := restoreFolders(getTestManifest(), true, restorer)
+	assert.Equal(t, 2, restored)
+	assert.Empty(t, failures)
+	assert.Equal(t, []string{"trash repo/a", "trash repo/d"}, restorer.calls)
+}
+
+func TestArtifactoryRestorer(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		mutex.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			if r.URL.Query().Has("properties") {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				w.WriteHeader(http.StatusCreated)
+			}
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	restorer, err := newArtifactoryRestorer(&config.ServerDetails{ArtifactoryUrl: server.URL + "/artifactory/"})
+	assert.NoError(t, err)
+	assert.NoError(t, restorer.createFolder("repo/a/b"))
+	assert.NoError(t, restorer.setProperties("repo/a/b", map[string][]string{"owner": {"ci", "qa"}}))
+	assert.NoError(t, restorer.restoreFromTrash("repo/a"))
+	assert.Equal(t, []string{
+		"PUT /artifactory/repo/a/b/?",
+		"PUT /artifactory/api/storage/repo/a/b?recursive=0&properties=owner=ci%2Cqa",
+		"POST /artifactory/api/trash/restore/repo/a?to=repo%2Fa",
+	}, requests)
+}
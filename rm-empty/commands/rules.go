@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// The rules deciding which of the empty folders found may be deleted.
+type folderRules struct {
+	// Folders created or modified after this time are kept. Ignored if zero.
+	modifiedBefore time.Time
+	// Name patterns of files which do not prevent a folder from being considered empty.
+	// These files are deleted together with the folder.
+	ignoreFiles []string
+	// Folders whose full path matches one of these patterns are protected, and never deleted.
+	excludePatterns []*regexp.Regexp
+	// Folders holding one of these properties are protected, and never deleted.
+	excludeProps map[string][]string
+	// Folders less than minDepth levels below the root of the scan are kept. Ignored if zero.
+	minDepth int
+	// Folders more than maxDepth levels below the root of the scan are never deleted themselves, but they don't
+	// prevent their ancestors from being deleted. Ignored if zero.
+	maxDepth int
+	// The number of path segments of the root of the scan. The depth of the folders is relative to it.
+	rootSegments int
+}
+
+func newFolderRules(conf *foldersConfiguration, now time.Time) *folderRules {
+	rules := &folderRules{
+		ignoreFiles:     conf.ignoreFiles,
+		excludePatterns: conf.excludePatterns,
+		excludeProps:    conf.excludeProps,
+		minDepth:        conf.minDepth,
+		maxDepth:        conf.maxDepth,
+	}
+	if conf.minAge > 0 {
+		rules.modifiedBefore = now.Add(-conf.minAge)
+	}
+	return rules
+}
+
+// Returns a copy of the rules, measuring the depth of the folders from the provided root of the scan.
+func (r *folderRules) forRoot(root string) *folderRules {
+	rules := *r
+	rules.rootSegments = countSegments(root)
+	return &rules
+}
+
+// Returns true if the folder must be kept, even if it is empty.
+// Keeping a folder keeps all its ancestors as well.
+func (r *folderRules) keepFolder(folder *clientrtutils.ResultItem) bool {
+	if isRepo(folder.Path) || r.isProtected(folder) {
+		return true
+	}
+	if r.minDepth > 0 && r.depth(folder) < r.minDepth {
+		return true
+	}
+	return !r.modifiedBefore.IsZero() && !isModifiedBefore(folder, r.modifiedBefore)
+}
+
+// Returns true if the empty folder may be deleted itself. Folders deeper than maxDepth are only deleted together
+// with an empty ancestor.
+func (r *folderRules) deletable(folder *clientrtutils.ResultItem) bool {
+	return r.maxDepth == 0 || r.depth(folder) <= r.maxDepth
+}
+
+// Returns the number of levels of the folder below the root of the scan.
+func (r *folderRules) depth(folder *clientrtutils.ResultItem) int {
+	return countSegments(folder.Path) - r.rootSegments
+}
+
+func countSegments(path string) int {
+	if path = strings.Trim(path, "/"); path == "" {
+		return 0
+	}
+	return strings.Count(path, "/") + 1
+}
+
+// Returns true if the folder matches one of the exclude patterns, or holds one of the exclude properties.
+func (r *folderRules) isProtected(folder *clientrtutils.ResultItem) bool {
+	folderPath := strings.Trim(folder.Path, "/")
+	for _, pattern := range r.excludePatterns {
+		if pattern.MatchString(folderPath) {
+			log.Debug("Keeping", folderPath, "since it matches an exclude pattern")
+			return true
+		}
+	}
+	for _, property := range folder.Properties {
+		for _, value := range r.excludeProps[property.Key] {
+			if value == property.Value {
+				log.Debug("Keeping", folderPath, "since it holds the property", property.Key+"="+property.Value)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if the rules depend on the properties of the folders.
+func (r *folderRules) needsProperties() bool {
+	return len(r.excludeProps) > 0
+}
+
+// Returns true if the file should not prevent its folder from being considered empty.
+func (r *folderRules) ignoreFile(file *clientrtutils.ResultItem) bool {
+	name := path.Base(file.Path)
+	for _, pattern := range r.ignoreFiles {
+		// The patterns are validated by parseIgnoreFiles.
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Splits the comma-separated list of file name patterns, and validates each pattern.
+func parseIgnoreFiles(ignoreFilesFlag string) (patterns []string, err error) {
+	for _, pattern := range strings.Split(ignoreFilesFlag, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("wrong ignore-files pattern '%s': %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return
+}
+
+// Splits the semicolon-separated list of path patterns, and converts each pattern into a regular expression.
+// The patterns are matched against the full path of the folders, starting with the repository. A * matches any
+// sequence of characters, including slashes.
+func parseExcludePatterns(excludeFlag string) (patterns []*regexp.Regexp, err error) {
+	for _, pattern := range strings.Split(excludeFlag, ";") {
+		if pattern = strings.Trim(strings.TrimSpace(pattern), "/"); pattern == "" {
+			continue
+		}
+		regex := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		var compiled *regexp.Regexp
+		if compiled, err = regexp.Compile("^" + regex + "$"); err != nil {
+			return nil, fmt.Errorf("wrong exclude pattern '%s': %w", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+	return
+}
+
+// Parses a list of properties in the key1=value1,value2;key2=value3 format.
+func parseExcludeProps(excludePropsFlag string) (map[string][]string, error) {
+	if excludePropsFlag == "" {
+		return nil, nil
+	}
+	props, err := clientrtutils.ParseProperties(excludePropsFlag)
+	if err != nil {
+		return nil, fmt.Errorf("wrong exclude-props value: %w", err)
+	}
+	return props.ToMap(), nil
+}
+
+// Returns true if the item was both created and modified before the provided time.
+// Items with missing or unparsable timestamps are treated as recent, so that they are never deleted by mistake.
+func isModifiedBefore(item *clientrtutils.ResultItem, before time.Time) bool {
+	for _, timestamp := range []string{item.Created, item.Modified} {
+		parsed, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			log.Debug("Failed to parse the timestamp of", item.Path+":", err.Error())
+			return false
+		}
+		if !parsed.Before(before) {
+			return false
+		}
+	}
+	return true
+}
+
+// Parses a duration such as 30m, 1h or 7d. In addition to the units supported by time.ParseDuration,
+// d can be used for days.
+func parseAge(age string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(age, "d"); found {
+		daysCount, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(daysCount) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(age)
+}
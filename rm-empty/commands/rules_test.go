@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	clientrtutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAge(t *testing.T) {
+	var ages = []struct {
+		age      string
+		expected time.Duration
+		valid    bool
+	}{
+		{"30m", 30 * time.Minute, true},
+		{"1h", time.Hour, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{"1.5h", 90 * time.Minute, true},
+		{"xd", 0, false},
+		{"1w", 0, false},
+	}
+	for _, v := range ages {
+		actual, err := parseAge(v.age)
+		assert.Equal(t, v.valid, err == nil, "parseAge(%q) error: %v", v.age, err)
+		assert.Equal(t, v.expected, actual, "parseAge(%q)", v.age)
+	}
+}
+
+func TestIsModifiedBefore(t *testing.T) {
+	before := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	var items = []struct {
+		created  string
+		modified string
+		expected bool
+	}{
+		{"2021-01-01T10:00:00.000Z", "2021-01-01T11:00:00.000Z", true},
+		{"2021-01-01T10:00:00.000Z", "2021-01-01T13:00:00.000Z", false},
+		{"2021-01-01T10:00:00.000+03:00", "2021-01-01T14:00:00.000+03:00", true},
+		{"", "2021-01-01T11:00:00.000Z", false},
+		{"not-a-date", "2021-01-01T11:00:00.000Z", false},
+	}
+	for _, v := range items {
+		item := &clientrtutils.ResultItem{Path: "repo/folder", Created: v.created, Modified: v.modified}
+		assert.Equal(t, v.expected, isModifiedBefore(item, before), "isModifiedBefore(%q, %q)", v.created, v.modified)
+	}
+}
+
+func TestParseIgnoreFiles(t *testing.T) {
+	patterns, err := parseIgnoreFiles(" maven-metadata.xml*, *.md5,,_index ")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"maven-metadata.xml*", "*.md5", "_index"}, patterns)
+
+	_, err = parseIgnoreFiles("[a-")
+	assert.Error(t, err)
+}
+
+func TestParseExcludePatterns(t *testing.T) {
+	patterns, err := parseExcludePatterns("repo/placeholders/*; */.keep ;;/repo/a/")
+	assert.NoError(t, err)
+	var expressions []string
+	for _, pattern := range patterns {
+		expressions = append(expressions, pattern.String())
+	}
+	assert.Equal(t, []string{`^repo/placeholders/.*$`, `^.*/\.keep$`, `^repo/a$`}, expressions)
+}
+
+func TestIsProtected(t *testing.T) {
+	patterns, err := parseExcludePatterns("repo/placeholders/*;*/.keep;repo/a")
+	assert.NoError(t, err)
+	props, err := parseExcludeProps("keep=true;owner=ci,qa")
+	assert.NoError(t, err)
+	rules := &folderRules{excludePatterns: patterns, excludeProps: props}
+	var folders = []struct {
+		folder   clientrtutils.ResultItem
+		expected bool
+	}{
+		{clientrtutils.ResultItem{Path: "repo/placeholders/x/y"}, true},
+		{clientrtutils.ResultItem{Path: "repo/placeholders"}, false},
+		{clientrtutils.ResultItem{Path: "repo/x/y/.keep"}, true},
+		{clientrtutils.ResultItem{Path: "repo/a/"}, true},
+		{clientrtutils.ResultItem{Path: "repo/ab"}, false},
+		{clientrtutils.ResultItem{Path: "repo/b", Properties: []clientrtutils.Property{{Key: "keep", Value: "true"}}}, true},
+		{clientrtutils.ResultItem{Path: "repo/b", Properties: []clientrtutils.Property{{Key: "keep", Value: "false"}}}, false},
+		{clientrtutils.ResultItem{Path: "repo/b", Properties: []clientrtutils.Property{{Key: "owner", Value: "qa"}}}, true},
+		{clientrtutils.ResultItem{Path: "repo/b", Properties: []clientrtutils.Property{{Key: "other", Value: "true"}}}, false},
+	}
+	for _, v := range folders {
+		assert.Equal(t, v.expected, rules.isProtected(&v.folder), "isProtected(%+v)", v.folder)
+	}
+}
+
+func TestParseExcludeProps(t *testing.T) {
+	props, err := parseExcludeProps("")
+	assert.NoError(t, err)
+	assert.Nil(t, props)
+
+	props, err = parseExcludeProps("keep=true;owner=ci,qa")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"keep": {"true"}, "owner": {"ci", "qa"}}, props)
+
+	_, err = parseExcludeProps("keep")
+	assert.Error(t, err)
+}
+
+func TestFolderDepth(t *testing.T) {
+	rules := (&folderRules{minDepth: 2, maxDepth: 3}).forRoot("/repo/a/")
+	var folders = []struct {
+		path      string
+		depth     int
+		keep      bool
+		deletable bool
+	}{
+		{"repo/a/b", 1, true, true},
+		{"repo/a/b/c/", 2, false, true},
+		{"repo/a/b/c/d", 3, false, true},
+		{"repo/a/b/c/d/e", 4, false, false},
+	}
+	for _, v := range folders {
+		folder := &clientrtutils.ResultItem{Path: v.path, Type: "folder"}
+		assert.Equal(t, v.depth, rules.depth(folder), "depth(%q)", v.path)
+		assert.Equal(t, v.keep, rules.keepFolder(folder), "keepFolder(%q)", v.path)
+		assert.Equal(t, v.deletable, rules.deletable(folder), "deletable(%q)", v.path)
+	}
+}
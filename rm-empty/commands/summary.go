@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// The exit codes of the folders command with the detailed-exit-code flag. Any other failure exits with 1.
+// The codes don't overlap the exit codes of the JFrog CLI, such as 2 for a command which affected nothing.
+const (
+	// No empty folders were found.
+	exitCodeNothingToDo = 0
+	// Empty folders were deleted, or found on a dry run.
+	exitCodeDeleted = 10
+	// Some of the empty folders failed to be deleted.
+	exitCodePartialFailure = 11
+	// Empty folders were found, but the user didn't allow deleting them.
+	exitCodeNotConfirmed = 12
+)
+
+// The outcome of a single run of the folders command.
+type runSummary struct {
+	Started string `json:"started"`
+	// The paths scanned, after resolving the repository wildcards.
+	Paths  []string `json:"paths"`
+	DryRun bool     `json:"dryRun,omitempty"`
+	// The number of files and folders read while scanning the paths.
+	Scanned         int     `json:"scanned"`
+	Found           int     `json:"found"`
+	Deleted         int     `json:"deleted"`
+	Failed          int     `json:"failed"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+	// True if empty folders were found, but the user didn't allow deleting them.
+	NotConfirmed bool `json:"notConfirmed,omitempty"`
+}
+
+// Logs the summary of the run as JSON, and appends it to the history file if historyPath isn't empty.
+func recordRun(summary *runSummary, err error, historyPath string) {
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	summaryJson, e := json.Marshal(summary)
+	if e != nil {
+		log.Error("Failed writing the summary of the run:", e)
+		return
+	}
+	log.Info("Run summary:", string(summaryJson))
+	if historyPath == "" {
+		return
+	}
+	if e = appendHistory(historyPath, summaryJson); e != nil {
+		log.Error("Failed writing the summary of the run to the history file:", e)
+	}
+}
+
+// Returns the error the folders command exits with when the detailed-exit-code flag is set.
+// The error carries the exit code of the outcome of the run, and is nil when there was nothing to do.
+func getDetailedExitError(summary *runSummary, err error) error {
+	var exitCode int
+	switch {
+	case summary.Failed > 0:
+		exitCode = exitCodePartialFailure
+	case err != nil:
+		return err
+	case summary.Deleted > 0 || (summary.DryRun && summary.Found > 0):
+		exitCode = exitCodeDeleted
+	case summary.NotConfirmed:
+		exitCode = exitCodeNotConfirmed
+	default:
+		return nil
+	}
+	cliError := coreutils.CliError{ExitCode: coreutils.ExitCode{Code: exitCode}}
+	if err != nil {
+		cliError.ErrorMsg = err.Error()
+	}
+	return cliError
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDetailedExitError(t *testing.T) {
+	scanErr := errors.New("failed scanning repo/")
+	deleteErr := errors.New("failed deleting 1 of 3 empty folders")
+	var outcomes = []struct {
+		name     string
+		summary  *runSummary
+		err      error
+		exitCode int
+		errorMsg string
+	}{
+		{"nothing to do", &runSummary{Scanned: 10}, nil, exitCodeNothingToDo, ""},
+		{"deleted", &runSummary{Found: 3, Deleted: 3}, nil, exitCodeDeleted, ""},
+		{"dry run", &runSummary{DryRun: true, Found: 3}, nil, exitCodeDeleted, ""},
+		{"not confirmed", &runSummary{Found: 3, NotConfirmed: true}, nil, exitCodeNotConfirmed, ""},
+		{"partial failure", &runSummary{Found: 3, Deleted: 2, Failed: 1}, deleteErr, exitCodePartialFailure, deleteErr.Error()},
+		{"failure", &runSummary{}, scanErr, coreutils.ExitCodeError.Code, scanErr.Error()},
+	}
+	for _, v := range outcomes {
+		t.Run(v.name, func(t *testing.T) {
+			err := getDetailedExitError(v.summary, v.err)
+			var cliError coreutils.CliError
+			switch {
+			case errors.As(err, &cliError):
+				assert.Equal(t, v.exitCode, cliError.Code)
+				assert.Equal(t, v.errorMsg, cliError.ErrorMsg)
+			case err != nil:
+				assert.Equal(t, coreutils.ExitCodeError.Code, v.exitCode)
+				assert.EqualError(t, err, v.errorMsg)
+			default:
+				assert.Equal(t, exitCodeNothingToDo, v.exitCode)
+			}
+		})
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	summary := &runSummary{Started: "2024-01-01T00:00:00Z", Paths: []string{"repo/"}, Scanned: 5, Found: 1}
+	recordRun(summary, errors.New("failed deleting 1 of 1 empty folders"), historyPath)
+	assert.Equal(t, "failed deleting 1 of 1 empty folders", summary.Error)
+
+	history, err := os.ReadFile(historyPath)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"started":"2024-01-01T00:00:00Z","paths":["repo/"],"scanned":5,"found":1,"deleted":0,"failed":0,"durationSeconds":0,"error":"failed deleting 1 of 1 empty folders"}`+"\n", string(history))
+}
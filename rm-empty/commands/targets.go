@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+// Checks that either paths or the all-local-repos flag are provided to the command, and returns the value of the flag.
+func getAllLocalReposFlagValue(c *components.Context) (allLocalRepos bool, err error) {
+	allLocalRepos = c.GetBoolFlagValue("all-local-repos")
+	if allLocalRepos && len(c.Arguments) > 0 {
+		return false, errors.New("wrong number of arguments. Paths cannot be provided together with the all-local-repos flag")
+	}
+	if !allLocalRepos && len(c.Arguments) == 0 {
+		return false, errors.New("wrong number of arguments. Expected: at least 1 path, or the all-local-repos flag")
+	}
+	return
+}
+
+// Resolves the paths provided to the command into the paths to scan for empty folders.
+// With allLocalRepos, the roots of all the local repositories are scanned.
+// Otherwise, wildcards in the repository part of a path, such as libs-*-local/, are matched against the local repositories.
+// Paths nested in other paths are dropped, since they are scanned as part of the outer path.
+func resolveTargets(paths []string, allLocalRepos bool, listLocalRepos func() ([]string, error)) (targets []string, err error) {
+	var localRepos []string
+	getLocalRepos := func() ([]string, error) {
+		if localRepos == nil {
+			if localRepos, err = listLocalRepos(); err != nil {
+				return nil, err
+			}
+			sort.Strings(localRepos)
+		}
+		return localRepos, nil
+	}
+
+	if allLocalRepos {
+		if localRepos, err = getLocalRepos(); err != nil {
+			return
+		}
+		for _, repo := range localRepos {
+			targets = append(targets, repo+"/")
+		}
+		return targets, nil
+	}
+
+	for _, targetPath := range paths {
+		repo, pathInRepo, _ := strings.Cut(strings.TrimPrefix(targetPath, "/"), "/")
+		if !strings.Contains(repo, "*") {
+			targets = append(targets, targetPath)
+			continue
+		}
+		if _, err = path.Match(repo, ""); err != nil {
+			return nil, fmt.Errorf("wrong repository pattern '%s': %w", repo, err)
+		}
+		if localRepos, err = getLocalRepos(); err != nil {
+			return
+		}
+		matched := false
+		for _, localRepo := range localRepos {
+			if match, _ := path.Match(repo, localRepo); match {
+				targets = append(targets, localRepo+"/"+pathInRepo)
+				matched = true
+			}
+		}
+		if !matched {
+			log.Warn("No local repositories match", targetPath)
+		}
+	}
+	return removeNestedTargets(targets), nil
+}
+
+// Removes duplicate paths and paths nested in other paths of the list.
+func removeNestedTargets(targets []string) []string {
+	// Compare the paths as folders, so that repo/a doesn't contain repo/ab.
+	asFolder := func(target string) string {
+		return strings.Trim(target, "/") + "/"
+	}
+	sorted := append([]string(nil), targets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return asFolder(sorted[i]) < asFolder(sorted[j])
+	})
+
+	var result []string
+	for _, target := range sorted {
+		if len(result) > 0 && strings.HasPrefix(asFolder(target), asFolder(result[len(result)-1])) {
+			log.Debug("Skipping", target, "since it is scanned as part of", result[len(result)-1])
+			continue
+		}
+		result = append(result, target)
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listTestLocalRepos() ([]string, error) {
+	return []string{"libs-release-local", "libs-snapshot-local", "generic-local", "libs-release-remote-cache"}, nil
+}
+
+func TestResolveTargets(t *testing.T) {
+	var targets = []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{"plain paths", []string{"generic-local/a/", "libs-release-local/"}, []string{"generic-local/a/", "libs-release-local/"}},
+		{"repository wildcard", []string{"libs-*-local/"}, []string{"libs-release-local/", "libs-snapshot-local/"}},
+		{"repository wildcard with path", []string{"libs-*-local/org/jfrog/"}, []string{"libs-release-local/org/jfrog/", "libs-snapshot-local/org/jfrog/"}},
+		{"no matching repositories", []string{"docker-*/"}, nil},
+		{"nested paths", []string{"generic-local/a/b/", "generic-local/a", "generic-local/ab/"}, []string{"generic-local/a", "generic-local/ab/"}},
+		{"duplicate paths", []string{"libs-*-local/", "libs-release-local/"}, []string{"libs-release-local/", "libs-snapshot-local/"}},
+	}
+	for _, v := range targets {
+		t.Run(v.name, func(t *testing.T) {
+			actual, err := resolveTargets(v.paths, false, listTestLocalRepos)
+			assert.NoError(t, err)
+			assert.Equal(t, v.expected, actual)
+		})
+	}
+}
+
+func TestResolveTargetsAllLocalRepos(t *testing.T) {
+	actual, err := resolveTargets(nil, true, listTestLocalRepos)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"generic-local/", "libs-release-local/", "libs-release-remote-cache/", "libs-snapshot-local/"}, actual)
+}
+
+func TestResolveTargetsListsRepositoriesOnlyForWildcards(t *testing.T) {
+	calls := 0
+	listLocalRepos := func() ([]string, error) {
+		calls++
+		return listTestLocalRepos()
+	}
+	_, err := resolveTargets([]string{"generic-local/"}, false, listLocalRepos)
+	assert.NoError(t, err)
+	assert.Zero(t, calls)
+
+	_, err = resolveTargets([]string{"libs-*-local/", "generic-*/"}, false, listLocalRepos)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestResolveTargetsErrors(t *testing.T) {
+	_, err := resolveTargets([]string{"libs-*[-local/"}, false, listTestLocalRepos)
+	assert.Error(t, err)
+
+	_, err = resolveTargets([]string{"libs-*-local/"}, false, func() ([]string, error) {
+		return nil, errors.New("listing failed")
+	})
+	assert.EqualError(t, err, "listing failed")
+}
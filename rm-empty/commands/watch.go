@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jfrog/jfrog-cli-core/v2/utils/coreutils"
+	"github.com/jfrog/jfrog-client-go/utils/log"
+)
+
+const (
+	defaultWatchInterval = "24h"
+	// The name of the history file in the JFrog CLI home directory, used by default in watch mode.
+	defaultHistoryFileName = "rm-empty-history.jsonl"
+)
+
+// Returns a context which is canceled when an interrupt or a termination signal is received.
+// Once canceled, the default behavior of the signals is restored, so that a second signal stops the process immediately.
+func newSignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// Runs the folders command every interval, until the context is canceled. A run in progress is completed before
+// returning. A failed run doesn't stop the following runs. The summary of each run is logged, and appended to the
+// history file if historyPath isn't empty.
+// Returns the error of the last run, so that the command fails if it stopped after a failed run.
+func watchEmptyFolders(ctx context.Context, interval time.Duration, historyPath string, run func() (*runSummary, error)) (lastErr error) {
+	log.Info("Watching for empty folders every", interval.String()+". Send an interrupt signal to stop.")
+	for ctx.Err() == nil {
+		summary, err := run()
+		if err != nil {
+			log.Error(err)
+		}
+		recordRun(summary, err, historyPath)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+		log.Info("The next run starts at", time.Now().Add(interval).Format(time.RFC3339))
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+	log.Info("Received a signal. Stopped watching for empty folders.")
+	if lastErr != nil {
+		return fmt.Errorf("the last run failed: %w", lastErr)
+	}
+	return nil
+}
+
+// Appends a line to the history file, creating the file and its folder if needed.
+func appendHistory(historyPath string, line []byte) (err error) {
+	if err = os.MkdirAll(filepath.Dir(historyPath), 0700); err != nil {
+		return
+	}
+	historyFile, err := os.OpenFile(historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := historyFile.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+	_, err = historyFile.Write(append(line, '\n'))
+	return
+}
+
+// Returns the path of the history file used by default in watch mode, in the JFrog CLI home directory.
+func getDefaultHistoryPath() (string, error) {
+	homeDir, err := coreutils.GetJfrogHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, defaultHistoryFileName), nil
+}
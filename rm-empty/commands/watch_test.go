@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchEmptyFolders(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history", "history.jsonl")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	err := watchEmptyFolders(ctx, time.Millisecond, historyPath, func() (*runSummary, error) {
+		runs++
+		summary := &runSummary{Paths: []string{"repo/"}, Found: runs, Deleted: runs}
+		switch runs {
+		case 2:
+			// A failed run doesn't stop the following runs.
+			return summary, errors.New("failed scanning repo/")
+		case 3:
+			cancel()
+		}
+		return summary, nil
+	})
+	assert.Equal(t, 3, runs)
+	// The last run succeeded.
+	assert.NoError(t, err)
+
+	history, err := os.ReadFile(historyPath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(history)), "\n")
+	assert.Len(t, lines, 3)
+	var summaries []runSummary
+	for _, line := range lines {
+		summary := runSummary{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &summary))
+		summaries = append(summaries, summary)
+	}
+	assert.Equal(t, []runSummary{
+		{Paths: []string{"repo/"}, Found: 1, Deleted: 1},
+		{Paths: []string{"repo/"}, Found: 2, Deleted: 2, Error: "failed scanning repo/"},
+		{Paths: []string{"repo/"}, Found: 3, Deleted: 3},
+	}, summaries)
+}
+
+func TestWatchEmptyFoldersLastRunFailed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := watchEmptyFolders(ctx, time.Millisecond, "", func() (*runSummary, error) {
+		cancel()
+		return &runSummary{}, errors.New("failed scanning repo/")
+	})
+	assert.EqualError(t, err, "the last run failed: failed scanning repo/")
+}
+
+func TestWatchEmptyFoldersCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, watchEmptyFolders(ctx, time.Hour, "", func() (*runSummary, error) {
+		t.Error("unexpected run after the context was canceled")
+		return &runSummary{}, nil
+	}))
+}
+
+func TestWatchEmptyFoldersStopsWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, watchEmptyFolders(ctx, time.Hour, "", func() (*runSummary, error) {
+			runs++
+			return &runSummary{}, nil
+		}))
+	}()
+	// The signal is received while waiting for the next run.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchEmptyFolders didn't stop after the context was canceled")
+	}
+	assert.Equal(t, 1, runs)
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
+	"github.com/jfrog/jfrog-client-go/artifactory"
+	"github.com/jfrog/jfrog-client-go/artifactory/services"
+	searchutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+// The Artifactory operations the cleanup depends on.
+// Keeping them behind an interface allows running the whole cleanup flow against a fake backend in tests.
+type cleanupBackend interface {
+	// Runs the AQL query and returns a reader of the ResultItems found.
+	searchAql(aqlQuery string) (*content.ContentReader, error)
+	// Deletes the ResultItems in the reader and returns the number of artifacts deleted.
+	deleteFiles(reader *content.ContentReader) (int, error)
+	// Moves the ResultItems in the reader to the same paths in the target repository and returns the number of artifacts moved.
+	moveFiles(reader *content.ContentReader, targetRepository string) (int, error)
+	// Reads the configuration of the repository into repoDetails.
+	getRepository(repoKey string, repoDetails interface{}) error
+}
+
+// A cleanupBackend which runs the operations against an Artifactory server.
+type artifactoryBackend struct {
+	rtConf         searchutils.CommonConf
+	serviceManager artifactory.ArtifactoryServicesManager
+}
+
+func newArtifactoryBackend(artifactoryDetails *config.ServerDetails) (*artifactoryBackend, error) {
+	authConfig, err := artifactoryDetails.CreateArtAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+	rtConf, err := searchutils.NewCommonConfImpl(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	serviceManager, err := utils.CreateServiceManager(artifactoryDetails, -1, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactoryBackend{rtConf: rtConf, serviceManager: serviceManager}, nil
+}
+
+func (b *artifactoryBackend) searchAql(aqlQuery string) (*content.ContentReader, error) {
+	return searchutils.ExecAqlSaveToFile(aqlQuery, b.rtConf)
+}
+
+func (b *artifactoryBackend) deleteFiles(reader *content.ContentReader) (int, error) {
+	return b.serviceManager.DeleteFiles(reader)
+}
+
+func (b *artifactoryBackend) moveFiles(reader *content.ContentReader, targetRepository string) (int, error) {
+	moveParams, err := buildMoveParams(reader, targetRepository)
+	if err != nil || len(moveParams) == 0 {
+		return 0, err
+	}
+	moved, _, err := b.serviceManager.Move(moveParams...)
+	return moved, err
+}
+
+// A folder inside a repository, as returned in a ResultItem.
+type itemFolder struct {
+	repo string
+	path string
+}
+
+// Returns the move parameters of the ResultItems in the reader, one for each of their folders,
+// so that a single AQL query finds all the items of a folder.
+func buildMoveParams(reader *content.ContentReader, targetRepository string) ([]services.MoveCopyParams, error) {
+	var folders []itemFolder
+	namesByFolder := make(map[itemFolder][]string)
+	for item := new(searchutils.ResultItem); reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		folder := itemFolder{repo: item.Repo, path: item.Path}
+		if _, exists := namesByFolder[folder]; !exists {
+			folders = append(folders, folder)
+		}
+		namesByFolder[folder] = append(namesByFolder[folder], fmt.Sprintf(`{"name":%q}`, item.Name))
+	}
+	if err := reader.GetError(); err != nil {
+		return nil, err
+	}
+
+	moveParams := make([]services.MoveCopyParams, 0, len(folders))
+	for _, folder := range folders {
+		params := services.NewMoveCopyParams()
+		params.Aql = searchutils.Aql{ItemsFind: fmt.Sprintf(`{"repo":%q,"path":%q,"$or":[%s]}`, folder.repo, folder.path, strings.Join(namesByFolder[folder], ","))}
+		params.Target = targetRepository + "/"
+		if folder.path != "." {
+			params.Target += folder.path + "/"
+		}
+		params.Flat = true
+		moveParams = append(moveParams, params)
+	}
+	return moveParams, nil
+}
+
+func (b *artifactoryBackend) getRepository(repoKey string, repoDetails interface{}) error {
+	return b.serviceManager.GetRepository(repoKey, repoDetails)
+}
@@ -0,0 +1,499 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	searchutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+// An in-process cleanupBackend, holding the artifacts of the repositories in memory.
+// The AQL queries of the cleanup are evaluated against the artifacts, so the selection rules are tested too.
+type fakeBackend struct {
+	items []fakeItem
+	// The repositories configurations returned by getRepository.
+	repositories map[string]interface{}
+	// Relative paths of artifacts that fail to be deleted.
+	failingPaths map[string]bool
+	deleted      []string
+	// The number of items in each delete request.
+	batches []int
+}
+
+// An artifact stored by the fakeBackend, with the fields the AQL queries of the cleanup filter by.
+type fakeItem struct {
+	searchutils.ResultItem
+	created, modified, updated time.Time
+	// The last download time. Zero if the artifact was never downloaded.
+	downloaded time.Time
+	// The creation time of the last build which used the artifact as a dependency. Zero if no build used it.
+	buildCreated time.Time
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		repositories: make(map[string]interface{}),
+		failingPaths: make(map[string]bool),
+	}
+}
+
+func (b *fakeBackend) searchAql(aqlQuery string) (*content.ContentReader, error) {
+	criteria, err := parseItemsFind(aqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range b.items {
+		match, err := item.matches(criteria)
+		if err != nil {
+			return nil, errors.Join(err, writer.Close())
+		}
+		if match {
+			writer.Write(item.ResultItem)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+}
+
+func (b *fakeBackend) deleteFiles(reader *content.ContentReader) (int, error) {
+	return b.forEachItem(reader, func(item *searchutils.ResultItem) {
+		b.deleted = append(b.deleted, item.GetItemRelativePath())
+	})
+}
+
+// The cleanup never moves artifacts, so any move request fails the test.
+func (b *fakeBackend) moveFiles(*content.ContentReader, string) (int, error) {
+	return 0, errors.New("unexpected move request")
+}
+
+func (b *fakeBackend) getRepository(repoKey string, repoDetails interface{}) error {
+	details, ok := b.repositories[repoKey]
+	if !ok {
+		return errors.New("repository not found: " + repoKey)
+	}
+	if target, ok := repoDetails.(*remoteRepositoryDetails); ok {
+		*target = *details.(*remoteRepositoryDetails)
+	}
+	return nil
+}
+
+// Runs the action on all the items of the reader which are not failing, and returns the number of items it ran on.
+// Like the Artifactory services, an error is returned if any of the items failed, after running on all the others.
+func (b *fakeBackend) forEachItem(reader *content.ContentReader, action func(item *searchutils.ResultItem)) (succeeded int, err error) {
+	length := 0
+	for item := new(searchutils.ResultItem); reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		length++
+		if b.failingPaths[item.GetItemRelativePath()] {
+			if err == nil {
+				err = errors.New("failed processing " + item.GetItemRelativePath())
+			}
+			continue
+		}
+		action(item)
+		succeeded++
+	}
+	b.batches = append(b.batches, length)
+	if readErr := reader.GetError(); readErr != nil {
+		return succeeded, readErr
+	}
+	return succeeded, err
+}
+
+// Returns the criteria of an items.find AQL query. The fields included by the query are ignored, since all the
+// fields of the items are returned.
+func parseItemsFind(aqlQuery string) (criteria map[string]interface{}, err error) {
+	body, found := strings.CutPrefix(aqlQuery, "items.find(")
+	if !found {
+		return nil, errors.New("unexpected AQL query: " + aqlQuery)
+	}
+	// Decode only the criteria object, which is followed by the rest of the query.
+	err = json.NewDecoder(strings.NewReader(body)).Decode(&criteria)
+	return
+}
+
+// Returns true if the item matches all the criteria. Only the fields and operators used by the cleanup are supported.
+func (item *fakeItem) matches(criteria map[string]interface{}) (bool, error) {
+	for field, value := range criteria {
+		var match bool
+		var err error
+		switch field {
+		case "$and", "$or":
+			match, err = item.matchesCriteriaList(field, value)
+		case "type", "repo", "path", "name":
+			match, err = matchString(item.getString(field), value)
+		case "stat.downloads":
+			match, err = matchDownloads(!item.downloaded.IsZero(), value)
+		case "created", "modified", "updated", "stat.downloaded", "dependency.module.build.created":
+			match, err = matchTime(item.getTime(field), value)
+		default:
+			err = errors.New("unsupported AQL field: " + field)
+		}
+		if err != nil || !match {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (item *fakeItem) matchesCriteriaList(operator string, value interface{}) (bool, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("unexpected %s value: %v", operator, value)
+	}
+	for _, element := range list {
+		criteria, ok := element.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("unexpected %s element: %v", operator, element)
+		}
+		match, err := item.matches(criteria)
+		if err != nil {
+			return false, err
+		}
+		if match == (operator == "$or") {
+			return match, nil
+		}
+	}
+	return operator == "$and", nil
+}
+
+func (item *fakeItem) getString(field string) string {
+	switch field {
+	case "type":
+		return item.Type
+	case "repo":
+		return item.Repo
+	case "path":
+		return item.Path
+	}
+	return item.Name
+}
+
+func (item *fakeItem) getTime(field string) time.Time {
+	switch field {
+	case "created":
+		return item.created
+	case "modified":
+		return item.modified
+	case "updated":
+		return item.updated
+	case "stat.downloaded":
+		return item.downloaded
+	}
+	return item.buildCreated
+}
+
+// Matches a string field against either an exact value or a $match pattern.
+func matchString(fieldValue string, value interface{}) (bool, error) {
+	if exact, ok := value.(string); ok {
+		return fieldValue == exact, nil
+	}
+	pattern, ok := getOperand(value, "$match").(string)
+	if !ok {
+		return false, fmt.Errorf("unsupported string criteria: %v", value)
+	}
+	return path.Match(pattern, fieldValue)
+}
+
+// Matches the download count against {"$gt":"0"} or {"$eq":null}, which is the count of artifacts never downloaded.
+func matchDownloads(downloaded bool, value interface{}) (bool, error) {
+	operators, ok := value.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("unsupported stat.downloads criteria: %v", value)
+	}
+	if operand, exists := operators["$eq"]; exists && operand == nil {
+		return !downloaded, nil
+	}
+	if operators["$gt"] == "0" {
+		return downloaded, nil
+	}
+	return false, fmt.Errorf("unsupported stat.downloads criteria: %v", value)
+}
+
+// Matches a time field against a relative time, such as {"$before":"6mo"} or {"$last":"30d"}.
+// A zero time, of an artifact never downloaded or not used by any build, matches neither.
+func matchTime(fieldValue time.Time, value interface{}) (bool, error) {
+	for _, operator := range []string{"$before", "$last"} {
+		relativeTime, ok := getOperand(value, operator).(string)
+		if !ok {
+			continue
+		}
+		threshold, err := parseRelativeTime(relativeTime)
+		if err != nil || fieldValue.IsZero() {
+			return false, err
+		}
+		return fieldValue.Before(threshold) == (operator == "$before"), nil
+	}
+	return false, fmt.Errorf("unsupported time criteria: %v", value)
+}
+
+func getOperand(value interface{}, operator string) interface{} {
+	if operators, ok := value.(map[string]interface{}); ok {
+		return operators[operator]
+	}
+	return nil
+}
+
+var relativeTimePattern = regexp.MustCompile(`^(\d+)(y|mo|w|d|h|mi|s)$`)
+
+// Returns the time which the AQL relative time, such as 17mo or 7200s, refers to.
+func parseRelativeTime(relativeTime string) (time.Time, error) {
+	parts := relativeTimePattern.FindStringSubmatch(relativeTime)
+	if parts == nil {
+		return time.Time{}, errors.New("unsupported relative time: " + relativeTime)
+	}
+	amount, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := time.Now()
+	switch parts[2] {
+	case "y":
+		return now.AddDate(-amount, 0, 0), nil
+	case "mo":
+		return now.AddDate(0, -amount, 0), nil
+	case "w":
+		return now.AddDate(0, 0, -7*amount), nil
+	case "d":
+		return now.AddDate(0, 0, -amount), nil
+	case "h":
+		return now.Add(-time.Duration(amount) * time.Hour), nil
+	case "mi":
+		return now.Add(-time.Duration(amount) * time.Minute), nil
+	}
+	return now.Add(-time.Duration(amount) * time.Second), nil
+}
+
+func daysAgo(days int) time.Time {
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// Artifacts which were never downloaded, and were created and last modified two years ago.
+func getTestItems() []fakeItem {
+	items := []fakeItem{
+		{ResultItem: searchutils.ResultItem{Repo: repo, Path: ".", Name: "a.zip", Type: "file", Size: 10}},
+		{ResultItem: searchutils.ResultItem{Repo: repo, Path: "org/apache", Name: "b.jar", Type: "file", Size: 20}},
+		{ResultItem: searchutils.ResultItem{Repo: repo, Path: "org/jfrog", Name: "c.jar", Type: "file", Size: 30}},
+		{ResultItem: searchutils.ResultItem{Repo: repo, Path: "org/jfrog", Name: "c.pom", Type: "file", Size: 40}},
+		{ResultItem: searchutils.ResultItem{Repo: repo, Path: "org/jfrog/1.0", Name: "d.jar", Type: "file", Size: 50}},
+	}
+	for i := range items {
+		items[i].created = daysAgo(730)
+		items[i].modified = daysAgo(730)
+		items[i].updated = daysAgo(730)
+	}
+	return items
+}
+
+func newTestBackend() *fakeBackend {
+	backend := newFakeBackend()
+	backend.items = getTestItems()
+	return backend
+}
+
+func setTestBatchSize(t *testing.T, size int) {
+	previous := batchSize
+	batchSize = size
+	t.Cleanup(func() {
+		batchSize = previous
+	})
+}
+
+func TestCleanArtifacts(t *testing.T) {
+	setTestBatchSize(t, 2)
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime}
+	backend := newTestBackend()
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 5, deleted: 5, bytesFreed: 150}, result)
+	assert.Equal(t, []int{2, 2, 1}, backend.batches)
+	assert.Len(t, backend.deleted, 5)
+}
+
+// Each artifact is on one side of the thresholds of the no-dl rule.
+func TestCleanArtifactsSelection(t *testing.T) {
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime}
+	backend := newFakeBackend()
+	newItem := func(name string, modified, downloaded time.Time) fakeItem {
+		return fakeItem{
+			ResultItem: searchutils.ResultItem{Repo: repo, Path: "org", Name: name, Type: "file", Size: 1},
+			created:    modified,
+			modified:   modified,
+			updated:    modified,
+			downloaded: downloaded,
+		}
+	}
+	otherRepoItem := newItem("other-repo.jar", daysAgo(730), time.Time{})
+	otherRepoItem.Repo = "other-repo"
+	folder := newItem("folder", daysAgo(730), time.Time{})
+	folder.Type = "folder"
+	backend.items = []fakeItem{
+		newItem("old-never-downloaded.jar", daysAgo(730), time.Time{}),
+		newItem("new-never-downloaded.jar", daysAgo(30), time.Time{}),
+		newItem("old-downloaded-long-ago.jar", daysAgo(730), daysAgo(600)),
+		newItem("old-downloaded-recently.jar", daysAgo(730), daysAgo(30)),
+		newItem("new-downloaded-long-ago.jar", daysAgo(30), daysAgo(600)),
+		otherRepoItem,
+		folder,
+	}
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 2, deleted: 2, bytesFreed: 2}, result)
+	assert.Equal(t, []string{repo + "/org/old-never-downloaded.jar", repo + "/org/old-downloaded-long-ago.jar"}, backend.deleted)
+}
+
+func TestCleanArtifactsExclusions(t *testing.T) {
+	_, exclusions, err := parseExclusions("org/apache/*;*.pom")
+	assert.NoError(t, err)
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime, exclusions: exclusions}
+	backend := newTestBackend()
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 3, deleted: 3, bytesFreed: 90}, result)
+	assert.ElementsMatch(t, []string{repo + "/a.zip", repo + "/org/jfrog/c.jar", repo + "/org/jfrog/1.0/d.jar"}, backend.deleted)
+}
+
+func TestCleanArtifactsDryRun(t *testing.T) {
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime, dryRun: true}
+	backend := newTestBackend()
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 5}, result)
+	assert.Empty(t, backend.batches)
+	assert.Empty(t, backend.deleted)
+}
+
+func TestCleanArtifactsPartialFailure(t *testing.T) {
+	setTestBatchSize(t, 2)
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime}
+	backend := newTestBackend()
+	backend.failingPaths[repo+"/org/apache/b.jar"] = true
+	backend.failingPaths[repo+"/org/jfrog/1.0/d.jar"] = true
+
+	// The failures in the first and last batches don't stop the batches after them.
+	result, err := cleanArtifacts(conf, backend)
+	assert.EqualError(t, err, "failed processing "+repo+"/org/apache/b.jar\nfailed processing "+repo+"/org/jfrog/1.0/d.jar")
+	assert.Equal(t, &cleanResult{matched: 5, deleted: 3, failed: 2, bytesFreed: 70, bytesFreedIsLowerBound: true}, result)
+	assert.Equal(t, []int{2, 2, 1}, backend.batches)
+	assert.Equal(t, []string{repo + "/a.zip", repo + "/org/jfrog/c.jar", repo + "/org/jfrog/c.pom"}, backend.deleted)
+}
+
+func TestBuildMoveParams(t *testing.T) {
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime}
+	reader, err := newTestBackend().searchAql(buildAQL(conf))
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	// The items of each folder are moved together.
+	moveParams, err := buildMoveParams(reader, "archive-repo")
+	assert.NoError(t, err)
+	var queries, targets []string
+	for _, params := range moveParams {
+		queries = append(queries, params.Aql.ItemsFind)
+		targets = append(targets, params.Target)
+		assert.True(t, params.Flat)
+	}
+	assert.Equal(t, []string{
+		`{"repo":"` + repo + `","path":".","$or":[{"name":"a.zip"}]}`,
+		`{"repo":"` + repo + `","path":"org/apache","$or":[{"name":"b.jar"}]}`,
+		`{"repo":"` + repo + `","path":"org/jfrog","$or":[{"name":"c.jar"},{"name":"c.pom"}]}`,
+		`{"repo":"` + repo + `","path":"org/jfrog/1.0","$or":[{"name":"d.jar"}]}`,
+	}, queries)
+	assert.Equal(t, []string{"archive-repo/", "archive-repo/org/apache/", "archive-repo/org/jfrog/", "archive-repo/org/jfrog/1.0/"}, targets)
+}
+
+func TestCleanArtifactsNoMatches(t *testing.T) {
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime}
+	backend := newTestBackend()
+	for i := range backend.items {
+		backend.items[i].modified = daysAgo(1)
+	}
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{}, result)
+	assert.Empty(t, backend.batches)
+}
+
+// Each artifact is on one side of the thresholds of the remote cache rules.
+func TestCleanArtifactsRemoteCache(t *testing.T) {
+	conf := &cleanConfiguration{repository: "maven-remote-cache", noDownloadedTime: noDlTime, remoteCache: true}
+	backend := newFakeBackend()
+	period := 600
+	backend.repositories["maven-remote"] = &remoteRepositoryDetails{RetrievalCachePeriodSecs: &period}
+	newItem := func(name string, created, updated, downloaded time.Time) fakeItem {
+		return fakeItem{
+			ResultItem: searchutils.ResultItem{Repo: "maven-remote-cache", Path: "org/jfrog", Name: name, Type: "file"},
+			created:    created,
+			modified:   created,
+			updated:    updated,
+			downloaded: downloaded,
+		}
+	}
+	now := time.Now()
+	backend.items = []fakeItem{
+		newItem("old-never-downloaded.jar", daysAgo(730), daysAgo(730), time.Time{}),
+		newItem("new-never-downloaded.jar", daysAgo(30), daysAgo(30), time.Time{}),
+		newItem("downloaded-long-ago.jar", daysAgo(30), daysAgo(30), daysAgo(600)),
+		newItem("downloaded-recently.jar", daysAgo(730), daysAgo(730), daysAgo(30)),
+		newItem("maven-metadata.xml", daysAgo(30), now.Add(-20*time.Minute), daysAgo(1)),
+		newItem("maven-metadata.xml.sha1", daysAgo(30), now.Add(-5*time.Minute), daysAgo(1)),
+		newItem("not-metadata.xml", daysAgo(30), now.Add(-20*time.Minute), daysAgo(1)),
+	}
+
+	// The metadata period is read from the configuration of the remote repository.
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 3, deleted: 3, metadataPeriodSecs: 600}, result)
+	assert.Equal(t, []string{
+		"maven-remote-cache/org/jfrog/old-never-downloaded.jar",
+		"maven-remote-cache/org/jfrog/downloaded-long-ago.jar",
+		"maven-remote-cache/org/jfrog/maven-metadata.xml",
+	}, backend.deleted)
+
+	// The metadata period of the configuration overrides the one of the remote repository.
+	backend.deleted = nil
+	conf.metadataPeriodSecs = 60
+	result, err = cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 4, deleted: 4, metadataPeriodSecs: 60}, result)
+	assert.Contains(t, backend.deleted, "maven-remote-cache/org/jfrog/maven-metadata.xml.sha1")
+}
+
+// The artifacts used by builds inside and outside the keep-build-deps period are kept and deleted respectively.
+func TestCleanArtifactsKeepBuildDependencies(t *testing.T) {
+	conf := &cleanConfiguration{repository: repo, noDownloadedTime: noDlTime, keepBuildDepsDays: 30}
+	backend := newTestBackend()
+	for i := range backend.items {
+		backend.items[i].Actual_Sha1 = fmt.Sprintf("sha1-%d", i)
+	}
+	// b.jar is used by a recent build through another repository.
+	usedThroughRemote := fakeItem{ResultItem: searchutils.ResultItem{Repo: "remote-cache", Path: "org/apache", Name: "b.jar", Type: "file", Actual_Sha1: "sha1-1"}, buildCreated: daysAgo(10)}
+	backend.items = append(backend.items, usedThroughRemote)
+	// d.jar is used by a recent build, and c.jar by an old one.
+	backend.items[4].buildCreated = daysAgo(10)
+	backend.items[2].buildCreated = daysAgo(60)
+
+	result, err := cleanArtifacts(conf, backend)
+	assert.NoError(t, err)
+	assert.Equal(t, &cleanResult{matched: 3, deleted: 3, bytesFreed: 80}, result)
+	assert.ElementsMatch(t, []string{repo + "/a.zip", repo + "/org/jfrog/c.jar", repo + "/org/jfrog/c.pom"}, backend.deleted)
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"fmt"
+
+	searchutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+)
+
+// The fields returned by the cleanup queries when the build dependencies are kept.
+// The sha1 is needed to match the artifacts against the build dependencies.
+const includeSha1 = `.include("repo","path","name","type","size","created","modified","actual_sha1")`
+
+func buildBuildDependenciesAQL(days int) string {
+	// Finds all artifacts used as dependencies by builds published in the last days.
+	// Dependencies are matched to artifacts by their sha1, so artifacts consumed through other repositories are found too.
+	return fmt.Sprintf(`items.find({"dependency.module.build.created":{"$last":"%dd"}}).include("actual_sha1")`, days)
+}
+
+// Returns the sha1 checksums of all the artifacts used as dependencies by builds published in the last days.
+func getBuildDependenciesSha1(backend cleanupBackend, days int) (map[string]bool, error) {
+	reader, err := backend.searchAql(buildBuildDependenciesAQL(days))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	checksums := make(map[string]bool)
+	for item := new(searchutils.ResultItem); reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		if item.Actual_Sha1 != "" {
+			checksums[item.Actual_Sha1] = true
+		}
+	}
+	return checksums, reader.GetError()
+}
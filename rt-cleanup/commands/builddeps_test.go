@@ -0,0 +1,12 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBuildDependenciesAQL(t *testing.T) {
+	expected := `items.find({"dependency.module.build.created":{"$last":"14d"}}).include("actual_sha1")`
+	assert.Equal(t, expected, buildBuildDependenciesAQL(14))
+}
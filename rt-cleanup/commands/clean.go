@@ -3,15 +3,18 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
 	"github.com/jfrog/jfrog-cli-core/v2/common/commands"
 	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
 	"github.com/jfrog/jfrog-cli-core/v2/utils/config"
 	searchutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
 	clientutils "github.com/jfrog/jfrog-client-go/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/jfrog/jfrog-client-go/utils/log"
 )
 
 func GetCleanCommand() components.Command {
@@ -37,7 +40,14 @@ func getCleanArguments() []components.Argument {
 
 func getCleanFlags() []components.Flag {
 	return []components.Flag{
-		components.NewStringFlag("server-id", "Artifactory server ID configured using the config command."),
+		components.NewStringFlag("server-id", "Artifactory server ID configured using the config command. A comma-separated list of server IDs can be used to clean several servers. The flag can't be repeated, since only its last value is used."),
+		components.NewBoolFlag("all-servers", "Clean the repository on all the configured Artifactory servers."),
+		components.NewBoolFlag("remote-cache", "Clean a remote repository cache using the remote cache rules. The repository name must end with -cache."),
+		components.NewStringFlag("metadata-period", "Used with remote-cache. Cached metadata files that were not updated for this number of seconds will be deleted. Defaults to the metadata retrieval cache period of the remote repository."),
+		components.NewStringFlag("exclusions", "A semicolon-separated list of path patterns inside the repository. Artifacts matching any of the patterns will not be deleted. The * and ? wildcards are supported."),
+		components.NewStringFlag("keep-build-deps", "Artifacts used as dependencies (matched by sha1) by builds published in the last keep-build-deps days will not be deleted."),
+		components.NewBoolFlag("dry-run", "List the artifacts that would be cleaned, without deleting them."),
+		components.NewStringFlag("format", "The output format. text and json are the allowed values. With json, a summary of the cleanup is printed to the standard output.", components.WithStrDefaultValue(textFormat)),
 		components.NewStringFlag("time-unit", "The time unit of the no-dl time. year, month and day are the allowed values.", components.WithStrDefaultValue("month")),
 		components.NewStringFlag("no-dl", "Artifacts that have not been downloaded or modified for at least no-dl will be deleted.", components.WithStrDefaultValue("1")),
 	}
@@ -52,6 +62,43 @@ func getCleanEnvVar() []components.EnvVar {
 type cleanConfiguration struct {
 	repository       string
 	noDownloadedTime string
+	remoteCache      bool
+	// If zero, the metadata retrieval cache period of the remote repository is used.
+	metadataPeriodSecs int
+	exclusionPatterns  []string
+	exclusions         []*regexp.Regexp
+	// If positive, artifacts used as dependencies by builds published in the last keepBuildDepsDays are not deleted.
+	keepBuildDepsDays int
+	dryRun            bool
+	format            string
+}
+
+// The number of artifacts sent to each delete request.
+var batchSize = 1000
+
+// The outcome of a cleanup on a single Artifactory server, or the sum of several.
+type cleanResult struct {
+	serverId string
+	matched  int
+	deleted  int
+	failed   int
+	// The total size of the batches in which all the artifacts were deleted.
+	bytesFreed int64
+	// True if some artifacts failed to be deleted, in which case bytesFreed doesn't count the artifacts deleted in the
+	// same batches, and the actual number of bytes freed may be higher.
+	bytesFreedIsLowerBound bool
+	duration               time.Duration
+	// The metadata period applied by the remote cache cleanup, which may differ between servers when it is read from
+	// the configurations of the remote repositories. Zero if the cleanup isn't a remote cache cleanup.
+	metadataPeriodSecs int
+}
+
+func (r *cleanResult) add(other *cleanResult) {
+	r.matched += other.matched
+	r.deleted += other.deleted
+	r.failed += other.failed
+	r.bytesFreed += other.bytesFreed
+	r.bytesFreedIsLowerBound = r.bytesFreedIsLowerBound || other.bytesFreedIsLowerBound
 }
 
 func cleanCmd(c *components.Context) error {
@@ -65,37 +112,205 @@ func cleanCmd(c *components.Context) error {
 		return err
 	}
 	conf.noDownloadedTime = noDownloadedTime
-	rtDetails, err := getRtDetails(c)
+	if conf.remoteCache = c.GetBoolFlagValue("remote-cache"); conf.remoteCache {
+		if !strings.HasSuffix(conf.repository, remoteCacheSuffix) {
+			return errors.New("the remote-cache flag can only be used with a remote repository cache. Received: " + conf.repository)
+		}
+		if c.IsFlagSet("metadata-period") {
+			if conf.metadataPeriodSecs, err = c.GetIntFlagValue("metadata-period"); err != nil {
+				return err
+			}
+			if conf.metadataPeriodSecs <= 0 {
+				return errors.New("metadata-period must be a positive number of seconds")
+			}
+		}
+	}
+	if conf.exclusionPatterns, conf.exclusions, err = parseExclusions(c.GetStringFlagValue("exclusions")); err != nil {
+		return err
+	}
+	if c.IsFlagSet("keep-build-deps") {
+		if conf.keepBuildDepsDays, err = c.GetIntFlagValue("keep-build-deps"); err != nil {
+			return err
+		}
+		if conf.keepBuildDepsDays <= 0 {
+			return errors.New("keep-build-deps must be a positive number of days")
+		}
+	}
+	conf.dryRun = c.GetBoolFlagValue("dry-run")
+	if conf.format = strings.ToLower(c.GetStringFlagValue("format")); conf.format != textFormat && conf.format != jsonFormat {
+		return errors.New("wrong format. Expected: text or json. Received: " + conf.format)
+	}
+	serverIds, err := getServerIds(c)
 	if err != nil {
 		return err
 	}
-	return cleanArtifacts(conf, rtDetails)
+	return cleanServers(conf, serverIds)
 }
 
-func cleanArtifacts(config *cleanConfiguration, artifactoryDetails *config.ServerDetails) error {
-	// Search for artifacts to delete using AQL
-	aqlQuery := buildAQL(config)
-	authConfig, err := artifactoryDetails.CreateArtAuthConfig()
+// Runs the cleanup against each of the provided servers. A failure on one server, including a failure to read its
+// configuration, does not stop the cleanup of the others, and all the failures are returned together at the end.
+func cleanServers(conf *cleanConfiguration, serverIds []string) error {
+	start := time.Now()
+	var errs []error
+	total := new(cleanResult)
+	summary := &cleanSummary{Repository: conf.repository, Rules: conf.rules()}
+	for _, serverId := range serverIds {
+		result, err := cleanServer(conf, serverId)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("server '%s': %w", result.serverId, err))
+		}
+		if err == nil || result.matched > 0 {
+			logCleanResult(result)
+		}
+		total.add(result)
+		summary.Servers = append(summary.Servers, newResultSummary(result, err))
+	}
+	total.duration = time.Since(start)
+	if len(serverIds) > 1 {
+		log.Info(fmt.Sprintf("Summary: %d servers, %d artifacts matched, %d deleted, %d failed, %d failed servers.",
+			len(serverIds), total.matched, total.deleted, total.failed, len(errs)))
+	}
+	if conf.format == jsonFormat {
+		summary.Total = newResultSummary(total, nil)
+		if err := printSummary(summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Runs the cleanup against a single server, or the default one if serverId is empty. The returned result is never nil.
+func cleanServer(conf *cleanConfiguration, serverId string) (result *cleanResult, err error) {
+	start := time.Now()
+	defer func() {
+		if result == nil {
+			result = new(cleanResult)
+		}
+		result.serverId = serverId
+		result.duration = time.Since(start)
+	}()
+	artifactoryDetails, err := getRtDetails(serverId)
 	if err != nil {
-		return err
+		return
 	}
-	rtConf, err := searchutils.NewCommonConfImpl(authConfig)
+	serverId = artifactoryDetails.ServerId
+	log.Info(fmt.Sprintf("Cleaning %s on server '%s'...", conf.repository, serverId))
+	backend, err := newArtifactoryBackend(artifactoryDetails)
 	if err != nil {
-		return err
+		return
+	}
+	return cleanArtifacts(conf, backend)
+}
+
+func logCleanResult(result *cleanResult) {
+	log.Info(fmt.Sprintf("Server '%s': %d artifacts matched, %d deleted, %d failed.", result.serverId, result.matched, result.deleted, result.failed))
+}
+
+func cleanArtifacts(config *cleanConfiguration, backend cleanupBackend) (*cleanResult, error) {
+	result := new(cleanResult)
+	// Search for artifacts to delete using AQL
+	aqlQuery := buildAQL(config)
+	if config.remoteCache {
+		result.metadataPeriodSecs = config.metadataPeriodSecs
+		if result.metadataPeriodSecs == 0 {
+			var err error
+			if result.metadataPeriodSecs, err = getMetadataPeriodSecs(backend, config.repository); err != nil {
+				return nil, err
+			}
+		}
+		aqlQuery = buildRemoteCacheAQL(config, result.metadataPeriodSecs)
+	}
+	var buildDependencies map[string]bool
+	if config.keepBuildDepsDays > 0 {
+		var err error
+		if buildDependencies, err = getBuildDependenciesSha1(backend, config.keepBuildDepsDays); err != nil {
+			return nil, err
+		}
+		aqlQuery += includeSha1
 	}
-	resultReader, err := searchutils.ExecAqlSaveToFile(aqlQuery, rtConf)
+	resultReader, err := backend.searchAql(aqlQuery)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resultReader.Close()
 
+	// Drop the excluded artifacts, and the artifacts used by recent builds, from the results
+	if len(config.exclusions) > 0 || len(buildDependencies) > 0 {
+		if resultReader, err = filterResults(resultReader, func(item *searchutils.ResultItem) bool {
+			return !isExcluded(item, config.exclusions) && !buildDependencies[item.Actual_Sha1]
+		}); err != nil {
+			return nil, err
+		}
+		defer resultReader.Close()
+	}
+
+	if result.matched, err = resultReader.Length(); err != nil {
+		return nil, err
+	}
+
+	if config.dryRun {
+		return result, logDryRun(resultReader)
+	}
+
 	// Delete the artifacts we found
-	serviceManager, err := utils.CreateServiceManager(artifactoryDetails, -1, 0, false)
+	result.deleted, result.bytesFreed, err = runInBatches(resultReader, result.matched, backend.deleteFiles)
+	result.failed = result.matched - result.deleted
+	result.bytesFreedIsLowerBound = result.failed > 0
+	return result, err
+}
+
+// Logs the artifacts in the reader, instead of deleting them.
+func logDryRun(reader *content.ContentReader) error {
+	for item := new(searchutils.ResultItem); reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		log.Info("[Dry run] Would clean", item.GetItemRelativePath())
+	}
+	return reader.GetError()
+}
+
+// Splits the items of the reader into batches of up to batchSize items, and runs the action on each batch,
+// so that the progress of long cleanups can be reported. A failed batch doesn't stop the following batches,
+// and the errors of all the failed batches are returned together at the end.
+// Returns the total number of items the action succeeded on, and the total size of the batches it succeeded on entirely.
+// The size of a batch the action partially failed on is not counted, since the items it failed on are unknown.
+func runInBatches(reader *content.ContentReader, total int, action func(batch *content.ContentReader) (int, error)) (succeeded int, succeededSize int64, err error) {
+	var errs []error
+	for processed := 0; processed < total; {
+		batch, batchLength, batchBytes, readErr := readBatch(reader)
+		if readErr != nil || batchLength == 0 {
+			errs = append(errs, readErr)
+			break
+		}
+		batchSucceeded, batchErr := action(batch)
+		errs = append(errs, batchErr, batch.Close())
+		succeeded += batchSucceeded
+		if batchSucceeded == batchLength {
+			succeededSize += batchBytes
+		}
+		processed += batchLength
+		log.Info(fmt.Sprintf("Processed %d/%d artifacts.", processed, total))
+	}
+	return succeeded, succeededSize, errors.Join(errs...)
+}
+
+// Reads the next batchSize items of the reader into a new reader.
+// Returns also the number of items read and their total size.
+func readBatch(reader *content.ContentReader) (batch *content.ContentReader, length int, size int64, err error) {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
 	if err != nil {
-		return err
+		return
 	}
-	_, err = serviceManager.DeleteFiles(resultReader)
-	return err
+	for item := new(searchutils.ResultItem); length < batchSize && reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		writer.Write(*item)
+		length++
+		size += item.Size
+	}
+	if err = writer.Close(); err != nil {
+		return
+	}
+	if err = reader.GetError(); err != nil || length == 0 {
+		return nil, 0, 0, err
+	}
+	return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), length, size, nil
 }
 
 func buildAQL(c *cleanConfiguration) (aqlQuery string) {
@@ -119,6 +334,62 @@ func buildAQL(c *cleanConfiguration) (aqlQuery string) {
 	return fmt.Sprintf(aqlQuery, c.repository, c.noDownloadedTime)
 }
 
+// Writes the items of the reader for which keep returns true into a new reader.
+func filterResults(reader *content.ContentReader, keep func(item *searchutils.ResultItem) bool) (*content.ContentReader, error) {
+	writer, err := content.NewContentWriter(content.DefaultKey, true, false)
+	if err != nil {
+		return nil, err
+	}
+	for item := new(searchutils.ResultItem); reader.NextRecord(item) == nil; item = new(searchutils.ResultItem) {
+		if keep(item) {
+			writer.Write(*item)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	if err = reader.GetError(); err != nil {
+		return nil, err
+	}
+	return content.NewContentReader(writer.GetFilePath(), content.DefaultKey), nil
+}
+
+// Converts the semicolon-separated exclusion patterns into regular expressions.
+// The patterns are relative to the repository root, * matches any sequence of characters (including "/")
+// and ? matches a single character.
+func parseExclusions(exclusionsFlag string) (patterns []string, exclusions []*regexp.Regexp, err error) {
+	for _, pattern := range strings.Split(exclusionsFlag, ";") {
+		pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		regex := regexp.QuoteMeta(pattern)
+		regex = strings.ReplaceAll(regex, `\*`, ".*")
+		regex = strings.ReplaceAll(regex, `\?`, ".")
+		var exclusion *regexp.Regexp
+		if exclusion, err = regexp.Compile("^" + regex + "$"); err != nil {
+			return nil, nil, err
+		}
+		patterns = append(patterns, pattern)
+		exclusions = append(exclusions, exclusion)
+	}
+	return
+}
+
+// Returns true if the path of the item inside its repository matches one of the exclusions.
+func isExcluded(item *searchutils.ResultItem, exclusions []*regexp.Regexp) bool {
+	itemPath := item.Name
+	if item.Path != "." {
+		itemPath = item.Path + "/" + item.Name
+	}
+	for _, exclusion := range exclusions {
+		if exclusion.MatchString(itemPath) {
+			return true
+		}
+	}
+	return false
+}
+
 // given the 2 inputs: timeUnit and time returns a string represents this time interval.
 // For example: 1, month => 1mo
 func parseTimeFlags(noDownloadedTime, timeUnit string) (timeString string, err error) {
@@ -143,9 +414,48 @@ func parseTimeFlags(noDownloadedTime, timeUnit string) (timeString string, err e
 
 }
 
+// Returns the IDs of all the servers the cleanup should run against.
+// These are either all the configured servers, the servers listed in server-id, or the default one, whose ID is empty.
+func getServerIds(c *components.Context) ([]string, error) {
+	serverIds := splitServerIds(c.GetStringFlagValue("server-id"))
+	if c.GetBoolFlagValue("all-servers") {
+		if len(serverIds) > 0 {
+			return nil, errors.New("the server-id and all-servers flags cannot be used together")
+		}
+		allServers, err := config.GetAllServersConfigs()
+		if err != nil {
+			return nil, err
+		}
+		if len(allServers) == 0 {
+			return nil, errors.New("no servers are configured. Use the config command to add servers")
+		}
+		for _, server := range allServers {
+			serverIds = append(serverIds, server.ServerId)
+		}
+	}
+	if len(serverIds) == 0 {
+		// Use the default server.
+		serverIds = []string{""}
+	}
+	return serverIds, nil
+}
+
+// Splits a comma-separated list of server IDs, ignoring empty and duplicate entries.
+func splitServerIds(serverIdsFlag string) (serverIds []string) {
+	seen := make(map[string]bool)
+	for _, serverId := range strings.Split(serverIdsFlag, ",") {
+		serverId = strings.TrimSpace(serverId)
+		if serverId == "" || seen[serverId] {
+			continue
+		}
+		seen[serverId] = true
+		serverIds = append(serverIds, serverId)
+	}
+	return
+}
+
 // Returns the Artifactory Details of the provided server-id, or the default one.
-func getRtDetails(c *components.Context) (*config.ServerDetails, error) {
-	serverId := c.GetStringFlagValue("server-id")
+func getRtDetails(serverId string) (*config.ServerDetails, error) {
 	details, err := commands.GetConfig(serverId, false)
 	if err != nil {
 		return nil, err
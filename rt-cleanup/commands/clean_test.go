@@ -3,23 +3,24 @@ package commands
 import (
 	"testing"
 
+	searchutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	repo = "testRepo"
-	time = "17mo"
-	aql  = `items.find({` +
+	repo     = "testRepo"
+	noDlTime = "17mo"
+	aql      = `items.find({` +
 		`"type":"file",` +
 		`"repo":"` + repo + `",` +
 		`"$or":[` +
 		`{"$and":[` +
-		`{"modified":{"$before":"` + time + `"}},` +
-		`{"stat.downloaded":{"$before":"` + time + `"}},` +
+		`{"modified":{"$before":"` + noDlTime + `"}},` +
+		`{"stat.downloaded":{"$before":"` + noDlTime + `"}},` +
 		`{"stat.downloads":{"$gt":"0"}}` +
 		`]},` +
 		`{"$and":[` +
-		`{"modified":{"$before":"` + time + `"}},` +
+		`{"modified":{"$before":"` + noDlTime + `"}},` +
 		`{"stat.downloads":{"$eq":null}}` +
 		`]}` +
 		`]` +
@@ -29,7 +30,7 @@ const (
 func TestBuildAQL(t *testing.T) {
 	conf := &cleanConfiguration{
 		repository:       repo,
-		noDownloadedTime: time,
+		noDownloadedTime: noDlTime,
 	}
 	assert.Equal(t, buildAQL(conf), aql)
 }
@@ -54,3 +55,42 @@ func TestParseTimeFlags(t *testing.T) {
 	}
 
 }
+
+func TestSplitServerIds(t *testing.T) {
+	var serverIdsFlags = []struct {
+		serverIdsFlag string
+		expected      []string
+	}{
+		{"", nil},
+		{"primary", []string{"primary"}},
+		{"primary,dr,edge", []string{"primary", "dr", "edge"}},
+		{" primary , dr ,,primary", []string{"primary", "dr"}},
+	}
+	for _, v := range serverIdsFlags {
+		assert.Equal(t, v.expected, splitServerIds(v.serverIdsFlag), "splitServerIds(%q)", v.serverIdsFlag)
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	_, exclusions, err := parseExclusions("org/apache/*; */1.0/*.jar;;com/example/?.pom/")
+	assert.NoError(t, err)
+	assert.Len(t, exclusions, 3)
+
+	var items = []struct {
+		path     string
+		name     string
+		expected bool
+	}{
+		{"org/apache/commons", "commons-io-2.4.jar", true},
+		{"org/jfrog", "a.jar", false},
+		{"com/jfrog/1.0", "lib.jar", true},
+		{"com/jfrog/1.0", "lib.pom", false},
+		{"com/example", "a.pom", true},
+		{"com/example", "ab.pom", false},
+		{".", "README.md", false},
+	}
+	for _, v := range items {
+		item := &searchutils.ResultItem{Path: v.path, Name: v.name}
+		assert.Equal(t, v.expected, isExcluded(item, exclusions), "isExcluded(%s/%s)", v.path, v.name)
+	}
+}
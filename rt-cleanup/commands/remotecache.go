@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	remoteCacheSuffix = "-cache"
+	// Artifactory's default metadata retrieval cache period of remote repositories.
+	defaultMetadataPeriodSecs = 7200
+)
+
+// Name patterns of the index and metadata files cached by remote repositories of the common package types.
+var metadataFilePatterns = []string{
+	"maven-metadata.xml*",
+	"index.yaml",
+	"repomd.xml",
+	"Packages*",
+	"Release",
+	"InRelease",
+}
+
+// The part of the remote repository configuration needed for the remote cache cleanup.
+type remoteRepositoryDetails struct {
+	RetrievalCachePeriodSecs *int `json:"retrievalCachePeriodSecs,omitempty"`
+}
+
+// Returns the metadata retrieval cache period of the remote repository behind the provided cache repository.
+func getMetadataPeriodSecs(backend cleanupBackend, cacheRepository string) (int, error) {
+	remoteRepository := strings.TrimSuffix(cacheRepository, remoteCacheSuffix)
+	details := new(remoteRepositoryDetails)
+	if err := backend.getRepository(remoteRepository, details); err != nil {
+		return 0, fmt.Errorf("failed to get the configuration of the remote repository '%s': %w", remoteRepository, err)
+	}
+	if details.RetrievalCachePeriodSecs == nil {
+		return defaultMetadataPeriodSecs, nil
+	}
+	return *details.RetrievalCachePeriodSecs, nil
+}
+
+func buildRemoteCacheAQL(c *cleanConfiguration, metadataPeriodSecs int) (aqlQuery string) {
+	// Finds all cached artifacts that haven't been downloaded since noDownloadedTime,
+	// and all cached metadata files that haven't been updated during the metadata retrieval cache period.
+	aqlQuery = `items.find({` +
+		`"type":"file",` +
+		`"repo":%[1]q,` +
+		`"$or":[` +
+		`{"$and":[` +
+		`{"stat.downloaded":{"$before":%[2]q}},` +
+		`{"stat.downloads":{"$gt":"0"}}` +
+		`]},` +
+		`{"$and":[` +
+		`{"created":{"$before":%[2]q}},` +
+		`{"stat.downloads":{"$eq":null}}` +
+		`]},` +
+		`{"$and":[` +
+		`{"$or":[%[3]s]},` +
+		`{"updated":{"$before":"%[4]ds"}}` +
+		`]}` +
+		`]` +
+		`})`
+
+	var metadataNames []string
+	for _, pattern := range metadataFilePatterns {
+		metadataNames = append(metadataNames, fmt.Sprintf(`{"name":{"$match":%q}}`, pattern))
+	}
+	return fmt.Sprintf(aqlQuery, c.repository, c.noDownloadedTime, strings.Join(metadataNames, ","), metadataPeriodSecs)
+}
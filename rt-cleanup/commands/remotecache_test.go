@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const remoteCacheAql = `items.find({` +
+	`"type":"file",` +
+	`"repo":"maven-remote-cache",` +
+	`"$or":[` +
+	`{"$and":[` +
+	`{"stat.downloaded":{"$before":"` + noDlTime + `"}},` +
+	`{"stat.downloads":{"$gt":"0"}}` +
+	`]},` +
+	`{"$and":[` +
+	`{"created":{"$before":"` + noDlTime + `"}},` +
+	`{"stat.downloads":{"$eq":null}}` +
+	`]},` +
+	`{"$and":[` +
+	`{"$or":[` +
+	`{"name":{"$match":"maven-metadata.xml*"}},` +
+	`{"name":{"$match":"index.yaml"}},` +
+	`{"name":{"$match":"repomd.xml"}},` +
+	`{"name":{"$match":"Packages*"}},` +
+	`{"name":{"$match":"Release"}},` +
+	`{"name":{"$match":"InRelease"}}` +
+	`]},` +
+	`{"updated":{"$before":"7200s"}}` +
+	`]}` +
+	`]` +
+	`})`
+
+func TestBuildRemoteCacheAQL(t *testing.T) {
+	conf := &cleanConfiguration{
+		repository:       "maven-remote-cache",
+		noDownloadedTime: noDlTime,
+		remoteCache:      true,
+	}
+	assert.Equal(t, remoteCacheAql, buildRemoteCacheAQL(conf, 7200))
+}
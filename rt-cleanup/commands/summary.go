@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+)
+
+// The machine-readable summary of a cleanup, printed when the json format is used.
+type cleanSummary struct {
+	Repository string          `json:"repository"`
+	Rules      cleanRules      `json:"rules"`
+	Servers    []resultSummary `json:"servers"`
+	Total      resultSummary   `json:"total"`
+}
+
+// The rules applied by the cleanup. MetadataPeriodSecs is set only by the metadata-period flag, since the period
+// applied on each server is included in its result.
+type cleanRules struct {
+	NoDownloadedTime   string   `json:"noDownloadedTime"`
+	RemoteCache        bool     `json:"remoteCache"`
+	MetadataPeriodSecs int      `json:"metadataPeriodSecs,omitempty"`
+	Exclusions         []string `json:"exclusions,omitempty"`
+	KeepBuildDepsDays  int      `json:"keepBuildDepsDays,omitempty"`
+	DryRun             bool     `json:"dryRun"`
+}
+
+type resultSummary struct {
+	ServerId   string `json:"serverId,omitempty"`
+	Matched    int    `json:"matched"`
+	Deleted    int    `json:"deleted"`
+	Failed     int    `json:"failed"`
+	BytesFreed int64  `json:"bytesFreed"`
+	// True if the actual number of bytes freed may be higher than BytesFreed.
+	BytesFreedIsLowerBound bool    `json:"bytesFreedIsLowerBound"`
+	DurationSeconds        float64 `json:"durationSeconds"`
+	Error                  string  `json:"error,omitempty"`
+	// The metadata period applied on the server by the remote cache cleanup.
+	MetadataPeriodSecs int `json:"metadataPeriodSecs,omitempty"`
+}
+
+func (c *cleanConfiguration) rules() cleanRules {
+	return cleanRules{
+		NoDownloadedTime:   c.noDownloadedTime,
+		RemoteCache:        c.remoteCache,
+		MetadataPeriodSecs: c.metadataPeriodSecs,
+		Exclusions:         c.exclusionPatterns,
+		KeepBuildDepsDays:  c.keepBuildDepsDays,
+		DryRun:             c.dryRun,
+	}
+}
+
+func newResultSummary(result *cleanResult, err error) resultSummary {
+	summary := resultSummary{
+		ServerId:               result.serverId,
+		Matched:                result.matched,
+		Deleted:                result.deleted,
+		Failed:                 result.failed,
+		BytesFreed:             result.bytesFreed,
+		BytesFreedIsLowerBound: result.bytesFreedIsLowerBound,
+		MetadataPeriodSecs:     result.metadataPeriodSecs,
+		DurationSeconds:        result.duration.Seconds(),
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	return summary
+}
+
+func printSummary(summary *cleanSummary) error {
+	output, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
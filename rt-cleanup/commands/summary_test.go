@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResultSummary(t *testing.T) {
+	result := &cleanResult{serverId: "primary", matched: 5, deleted: 3, failed: 2, bytesFreed: 70, bytesFreedIsLowerBound: true, duration: 1500 * time.Millisecond}
+	summary := newResultSummary(result, errors.New("delete failed"))
+	output, err := json.Marshal(summary)
+	assert.NoError(t, err)
+	expected := `{"serverId":"primary","matched":5,"deleted":3,"failed":2,"bytesFreed":70,"bytesFreedIsLowerBound":true,"durationSeconds":1.5,"error":"delete failed"}`
+	assert.Equal(t, expected, string(output))
+}
+
+func TestNewResultSummaryRemoteCache(t *testing.T) {
+	result := &cleanResult{serverId: "edge", matched: 1, deleted: 1, metadataPeriodSecs: 600}
+	output, err := json.Marshal(newResultSummary(result, nil))
+	assert.NoError(t, err)
+	expected := `{"serverId":"edge","matched":1,"deleted":1,"failed":0,"bytesFreed":0,"bytesFreedIsLowerBound":false,"durationSeconds":0,"metadataPeriodSecs":600}`
+	assert.Equal(t, expected, string(output))
+}
+
+func TestCleanRules(t *testing.T) {
+	conf := &cleanConfiguration{noDownloadedTime: noDlTime, exclusionPatterns: []string{"org/*"}, keepBuildDepsDays: 7, dryRun: true}
+	output, err := json.Marshal(conf.rules())
+	assert.NoError(t, err)
+	expected := `{"noDownloadedTime":"17mo","remoteCache":false,"exclusions":["org/*"],"keepBuildDepsDays":7,"dryRun":true}`
+	assert.Equal(t, expected, string(output))
+}
@@ -42,9 +42,29 @@ func catCmd(c *components.Context) error {
 		return errors.New("cat: " + conf.path + " : Path must be in a form of `<repo>/<name>` or `<repo>/<dir>/<name>`.")
 	}
 
+	if isGlob(conf.path) {
+		return doGlobCat(conf)
+	}
 	return doCat(conf)
 }
 
+// Prints the content of each file matching the glob pattern, in the order of their paths.
+func doGlobCat(c *commonConfiguration) error {
+	matches, err := searchGlob(c, false, nil)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return errors.New("cat: " + c.path + ": No such file.")
+	}
+	for _, match := range matches {
+		if err = doCat(&commonConfiguration{details: c.details, path: match.Path}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func doCat(c *commonConfiguration) error {
 	// Create a temporary file for the download results
 	target, err := os.CreateTemp("", "rt-fs-cat")
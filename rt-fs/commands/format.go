@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+)
+
+// The machine-readable formats of ls
+const (
+	jsonFormat   = "json"
+	csvFormat    = "csv"
+	ndjsonFormat = "ndjson"
+)
+
+// The fields returned by the search for the machine-readable formats.
+var formatFields = []string{"type", "size", "created", "modified", "modified_by", "actual_sha1", "sha256", "actual_md5"}
+
+// The names of the fields of an lsRecord, used as the header of the csv format.
+var lsRecordFields = []string{"path", "type", "size", "created", "modified", "modified_by", "sha1", "sha256", "md5"}
+
+// An entry in the machine-readable formats of ls, holding its full path.
+type lsRecord struct {
+	Path       string `json:"path"`
+	Type       string `json:"type"`
+	Size       int64  `json:"size"`
+	Created    string `json:"created"`
+	Modified   string `json:"modified"`
+	ModifiedBy string `json:"modified_by"`
+	Sha1       string `json:"sha1"`
+	Sha256     string `json:"sha256"`
+	Md5        string `json:"md5"`
+}
+
+func newLsRecord(result *utils.SearchResult, fullPath string) lsRecord {
+	return lsRecord{
+		Path:       fullPath,
+		Type:       result.Type,
+		Size:       result.Size,
+		Created:    result.Created,
+		Modified:   result.Modified,
+		ModifiedBy: result.ModifiedBy,
+		Sha1:       result.Sha1,
+		Sha256:     result.Sha256,
+		Md5:        result.Md5,
+	}
+}
+
+func (record *lsRecord) csvRow() []string {
+	return []string{record.Path, record.Type, strconv.FormatInt(record.Size, 10), record.Created, record.Modified, record.ModifiedBy, record.Sha1, record.Sha256, record.Md5}
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case "", jsonFormat, csvFormat, ndjsonFormat:
+		return nil
+	}
+	return errors.New("unsupported format '" + format + "'. Possible values: json, csv and ndjson")
+}
+
+// Prints the entries which ls lists, one record per entry, in the machine-readable format.
+func doFormattedLs(c *lsConfiguration) error {
+	records, err := getLsRecords(c)
+	if err != nil {
+		return err
+	}
+	return printLsRecords(os.Stdout, c.format, records)
+}
+
+// Returns the records of the entries which ls lists. If the listing is recursive, the record of each folder is followed
+// by the records of its entries.
+func getLsRecords(c *lsConfiguration) ([]lsRecord, error) {
+	if !isGlob(c.path) {
+		root, err := c.searchFolder(c.path, formatFields)
+		if err != nil {
+			return nil, err
+		}
+		// A file is listed by itself.
+		if root.Type != "folder" {
+			return []lsRecord{newLsRecord(&root.SearchResult, root.Path)}, nil
+		}
+		return appendTreeRecords(nil, c, root, root.Path), nil
+	}
+
+	matches, err := searchGlob(c.commonConfiguration, true, formatFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, errors.New("ls: cannot access '" + c.path + "': No such file or directory")
+	}
+	// Like the listing of the matches, the files come first, followed by the entries of the folders.
+	sort.SliceStable(matches, func(i, j int) bool {
+		return c.lessEntry(&matches[i], &matches[j])
+	})
+	var records []lsRecord
+	var folders []utils.SearchResult
+	for i := range matches {
+		if matches[i].Type == "folder" {
+			folders = append(folders, matches[i])
+			continue
+		}
+		records = append(records, newLsRecord(&matches[i], matches[i].Path))
+	}
+	for _, folder := range folders {
+		root, err := c.searchFolder(folder.Path, formatFields)
+		if err != nil {
+			return nil, err
+		}
+		records = appendTreeRecords(records, c, root, root.Path)
+	}
+	return records, nil
+}
+
+// Appends the records of the entries under the folder, sorted by the sort flags. The record of each entry is followed
+// by the records of its own entries.
+func appendTreeRecords(records []lsRecord, c *lsConfiguration, folder *treeNode, folderPath string) []lsRecord {
+	for _, child := range c.sortedChildren(folder) {
+		childPath := folderPath + "/" + child.Path
+		records = append(records, newLsRecord(&child.SearchResult, childPath))
+		records = appendTreeRecords(records, c, child, childPath)
+	}
+	return records
+}
+
+// Prints the records in the format: a JSON array, one JSON object per line, or CSV with a header.
+func printLsRecords(out io.Writer, format string, records []lsRecord) error {
+	switch format {
+	case jsonFormat:
+		if records == nil {
+			records = []lsRecord{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case ndjsonFormat:
+		encoder := json.NewEncoder(out)
+		for i := range records {
+			if err := encoder.Encode(&records[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case csvFormat:
+		writer := csv.NewWriter(out)
+		if err := writer.Write(lsRecordFields); err != nil {
+			return err
+		}
+		for i := range records {
+			if err := writer.Write(records[i].csvRow()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return errors.New("unsupported format '" + format + "'")
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestRecords(t *testing.T) []lsRecord {
+	results := getTestTreeResults()
+	results[1].Created = "2020-08-24T07:59:57.521Z"
+	results[1].Modified = "2020-08-24T08:00:00.000Z"
+	results[1].ModifiedBy = "admin"
+	results[1].Sha1 = "55ca6286e3e4f4fba5d0448333fa99fc5a404a73"
+	results[1].Sha256 = "5a8c3b1f2c8b3ed7e0c4cd3c4d6a1d77fbc2ff0b6ea2c4f4c3d7d8e3b1a2c3d4"
+	results[1].Md5 = "ea6cbbee8ec4d2d93dd5d4c2b9ab64a1"
+	reader := createTestTreeReader(t, results)
+	defer reader.Close()
+	root, err := buildTree(reader, "libs-release-local/org", 0)
+	assert.NoError(t, err)
+	return appendTreeRecords(nil, &lsConfiguration{sortBySize: true}, root, root.Path)
+}
+
+func TestAppendTreeRecords(t *testing.T) {
+	var paths []string
+	for _, record := range getTestRecords(t) {
+		paths = append(paths, record.Path)
+	}
+	assert.Equal(t, []string{
+		"libs-release-local/org/app.jar",
+		"libs-release-local/org/empty",
+		"libs-release-local/org/jfrog",
+		"libs-release-local/org/jfrog/example",
+		"libs-release-local/org/jfrog/example/gradle",
+		"libs-release-local/org/jfrog/example/gradle/api-1.0.jar",
+		"libs-release-local/org/jfrog/example/gradle/api-1.0.pom",
+	}, paths)
+}
+
+var printLsRecordsProvider = []struct {
+	format   string
+	expected string
+}{
+	{jsonFormat, `[
+  {
+    "path": "libs-release-local/org/jfrog/example/gradle",
+    "type": "folder",
+    "size": 0,
+    "created": "",
+    "modified": "",
+    "modified_by": "",
+    "sha1": "",
+    "sha256": "",
+    "md5": ""
+  },
+  {
+    "path": "libs-release-local/org/jfrog/example/gradle/api-1.0.jar",
+    "type": "file",
+    "size": 1536,
+    "created": "2020-08-24T07:59:57.521Z",
+    "modified": "2020-08-24T08:00:00.000Z",
+    "modified_by": "admin",
+    "sha1": "55ca6286e3e4f4fba5d0448333fa99fc5a404a73",
+    "sha256": "5a8c3b1f2c8b3ed7e0c4cd3c4d6a1d77fbc2ff0b6ea2c4f4c3d7d8e3b1a2c3d4",
+    "md5": "ea6cbbee8ec4d2d93dd5d4c2b9ab64a1"
+  }
+]
+`},
+	{ndjsonFormat, `{"path":"libs-release-local/org/jfrog/example/gradle","type":"folder","size":0,"created":"","modified":"","modified_by":"","sha1":"","sha256":"","md5":""}
+{"path":"libs-release-local/org/jfrog/example/gradle/api-1.0.jar","type":"file","size":1536,"created":"2020-08-24T07:59:57.521Z","modified":"2020-08-24T08:00:00.000Z","modified_by":"admin","sha1":"55ca6286e3e4f4fba5d0448333fa99fc5a404a73","sha256":"5a8c3b1f2c8b3ed7e0c4cd3c4d6a1d77fbc2ff0b6ea2c4f4c3d7d8e3b1a2c3d4","md5":"ea6cbbee8ec4d2d93dd5d4c2b9ab64a1"}
+`},
+	{csvFormat, `path,type,size,created,modified,modified_by,sha1,sha256,md5
+libs-release-local/org/jfrog/example/gradle,folder,0,,,,,,
+libs-release-local/org/jfrog/example/gradle/api-1.0.jar,file,1536,2020-08-24T07:59:57.521Z,2020-08-24T08:00:00.000Z,admin,55ca6286e3e4f4fba5d0448333fa99fc5a404a73,5a8c3b1f2c8b3ed7e0c4cd3c4d6a1d77fbc2ff0b6ea2c4f4c3d7d8e3b1a2c3d4,ea6cbbee8ec4d2d93dd5d4c2b9ab64a1
+`},
+}
+
+func TestPrintLsRecords(t *testing.T) {
+	records := getTestRecords(t)[4:6]
+	for _, sample := range printLsRecordsProvider {
+		t.Run(sample.format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			assert.NoError(t, printLsRecords(out, sample.format, records))
+			assert.Equal(t, sample.expected, out.String())
+		})
+	}
+}
+
+func TestPrintLsRecordsEmpty(t *testing.T) {
+	for format, expected := range map[string]string{jsonFormat: "[]\n", ndjsonFormat: "", csvFormat: "path,type,size,created,modified,modified_by,sha1,sha256,md5\n"} {
+		t.Run(format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			assert.NoError(t, printLsRecords(out, format, nil))
+			assert.Equal(t, expected, out.String())
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{"", jsonFormat, csvFormat, ndjsonFormat} {
+		assert.NoError(t, checkFormat(format), fmt.Sprintf("format: %q", format))
+	}
+	assert.EqualError(t, checkFormat("xml"), "unsupported format 'xml'. Possible values: json, csv and ndjson")
+}
+
+func TestNewLsRecord(t *testing.T) {
+	result := &utils.SearchResult{Path: "api-1.0.jar", Type: "file", Size: 1536, Sha1: "sha1", Sha256: "sha256", Md5: "md5"}
+	assert.Equal(t, lsRecord{Path: "libs-release-local/api-1.0.jar", Type: "file", Size: 1536, Sha1: "sha1", Sha256: "sha256", Md5: "md5"}, newLsRecord(result, "libs-release-local/api-1.0.jar"))
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/generic"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/common/commands"
+	"github.com/jfrog/jfrog-cli-core/v2/common/spec"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+// Returns true if the path is a glob pattern, rather than a path of a single entry.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?")
+}
+
+// Converts a shell-style glob pattern into a regular expression matching full paths.
+// * and ? match any characters in a single path segment, and ** matches any number of path segments, including none.
+// Character classes aren't supported, so brackets are matched literally.
+func globToRegexp(glob string) *regexp.Regexp {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expression.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expression.WriteString("[^/]*")
+		case glob[i] == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
+
+// Searches for the entries matching the glob pattern, and returns them sorted by their paths.
+// The include fields are returned by the search, in addition to the default fields, if not empty.
+func searchGlob(c *commonConfiguration, includeDirs bool, include []string) ([]utils.SearchResult, error) {
+	glob := strings.TrimSuffix(c.path, "/")
+
+	// The wildcards of the file spec also match slashes, so the search returns all the matches of the glob pattern,
+	// and some other entries, which are filtered out by the matcher. A recursive search is only needed if the
+	// matches may be in more than one folder.
+	searchCmd := generic.NewSearchCommand()
+	searchSpec := spec.NewBuilder().Pattern(getGlobSearchPattern(glob)).IncludeDirs(includeDirs).Recursive(needsRecursiveSearch(glob)).Include(include).BuildSpec()
+	searchCmd.SetServerDetails(c.details).SetSpec(searchSpec)
+	if err := commands.Exec(searchCmd); err != nil {
+		return nil, err
+	}
+	reader := searchCmd.Result().Reader()
+	defer reader.Close()
+	return filterGlobMatches(reader, globToRegexp(glob))
+}
+
+// Returns the pattern of the file spec searching for the matches of the glob pattern. The ** segments are removed
+// rather than replaced by a wildcard, since they may match no segments at all, and a recursive search of the
+// remaining pattern returns the entries in any folder below. The rest of the wildcards become * wildcards.
+func getGlobSearchPattern(glob string) string {
+	for strings.Contains(glob, "/**/") {
+		glob = strings.ReplaceAll(glob, "/**/", "/")
+	}
+	return strings.NewReplacer("**", "*", "?", "*").Replace(glob)
+}
+
+// Returns true if the matches of the glob pattern may be in more than one folder of a repository, which happens if the
+// pattern holds ** or wildcards in the path of the folder. Wildcards in the name of the repository are matched by
+// a non-recursive search as well.
+func needsRecursiveSearch(glob string) bool {
+	if strings.Contains(glob, "**") {
+		return true
+	}
+	segments := strings.Split(glob, "/")
+	if len(segments) < 3 {
+		return false
+	}
+	return isGlob(strings.Join(segments[1:len(segments)-1], "/"))
+}
+
+// Returns the results in the reader whose paths match the glob pattern matcher, sorted by their paths.
+func filterGlobMatches(reader *content.ContentReader, matcher *regexp.Regexp) ([]utils.SearchResult, error) {
+	var matches []utils.SearchResult
+	for result := new(utils.SearchResult); reader.NextRecord(result) == nil; result = new(utils.SearchResult) {
+		result.Path = strings.TrimSuffix(result.Path, "/")
+		if matcher.MatchString(result.Path) {
+			matches = append(matches, *result)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Path < matches[j].Path
+	})
+	return matches, reader.GetError()
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	clientutils "github.com/jfrog/jfrog-client-go/artifactory/services/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var globToRegexpProvider = []struct {
+	glob     string
+	path     string
+	expected bool
+}{
+	{"libs-release-local/org/*/1.*/*.jar", "libs-release-local/org/app/1.0/app-1.0.jar", true},
+	{"libs-release-local/org/*/1.*/*.jar", "libs-release-local/org/app/2.0/app-2.0.jar", false},
+	{"libs-release-local/org/*/1.*/*.jar", "libs-release-local/org/app/1.0/app-1.0.pom", false},
+	{"libs-release-local/org/*/1.*/*.jar", "libs-release-local/org/jfrog/app/1.0/app-1.0.jar", false},
+	{"libs-release-local/org/*", "libs-release-local/org/app", true},
+	{"libs-release-local/org/*", "libs-release-local/org/app/1.0", false},
+	{"libs-release-local/org/*", "libs-release-local/org", false},
+	{"libs-release-local/**/*.jar", "libs-release-local/app.jar", true},
+	{"libs-release-local/**/*.jar", "libs-release-local/org/jfrog/app/1.0/app-1.0.jar", true},
+	{"libs-release-local/**/*.jar", "libs-release-local/org/jfrog/app/1.0/app-1.0.pom", false},
+	{"libs-release-local/org/**", "libs-release-local/org/jfrog/app", true},
+	{"libs-*/org", "libs-release-local/org", true},
+	{"libs-*/org", "libs-release-local/com/org", false},
+	{"libs-release-local/app-?.jar", "libs-release-local/app-1.jar", true},
+	{"libs-release-local/app-?.jar", "libs-release-local/app-10.jar", false},
+	{"libs-release-local/app-1.*", "libs-release-local/app-10.jar", false},
+	{"libs-release-local/(app)+.jar", "libs-release-local/(app)+.jar", true},
+	{"libs-release-local/(app)+.jar", "libs-release-local/appapp.jar", false},
+	{"libs-release-local/app-[0-9].jar", "libs-release-local/app-[0-9].jar", true},
+	{"libs-release-local/app-[0-9].jar", "libs-release-local/app-1.jar", false},
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	for _, sample := range globToRegexpProvider {
+		t.Run(fmt.Sprintf("%v", sample), func(t *testing.T) {
+			assert.Equal(t, sample.expected, globToRegexp(sample.glob).MatchString(sample.path))
+		})
+	}
+}
+
+func TestIsGlob(t *testing.T) {
+	assert.True(t, isGlob("libs-release-local/org/*.jar"))
+	assert.True(t, isGlob("libs-release-local/app-?.jar"))
+	assert.False(t, isGlob("libs-release-local/org/app.jar"))
+}
+
+var needsRecursiveSearchProvider = []struct {
+	glob     string
+	expected bool
+}{
+	{"libs-release-local/*", false},
+	{"libs-release-local/org/*.jar", false},
+	{"libs-release-local/org/app-?.jar", false},
+	{"libs-*/org", false},
+	{"libs-*/*", false},
+	{"libs-release-local/org/*/1.*/*.jar", true},
+	{"libs-release-local/org/?/app.jar", true},
+	{"libs-release-local/**/*.jar", true},
+	{"libs-release-local/org/**", true},
+}
+
+func TestNeedsRecursiveSearch(t *testing.T) {
+	for _, sample := range needsRecursiveSearchProvider {
+		t.Run(sample.glob, func(t *testing.T) {
+			assert.Equal(t, sample.expected, needsRecursiveSearch(sample.glob))
+		})
+	}
+}
+
+var getGlobSearchPatternProvider = []struct {
+	glob     string
+	expected string
+}{
+	{"libs-release-local/*", "libs-release-local/*"},
+	{"libs-release-local/app-?.jar", "libs-release-local/app-*.jar"},
+	{"libs-release-local/**/*.jar", "libs-release-local/*.jar"},
+	{"libs-release-local/org/**/*.jar", "libs-release-local/org/*.jar"},
+	{"libs-release-local/org/**/**/*.jar", "libs-release-local/org/*.jar"},
+	{"libs-release-local/org/**/1.*/*.jar", "libs-release-local/org/1.*/*.jar"},
+	{"libs-release-local/org/**", "libs-release-local/org/*"},
+	{"libs-release-local/org/app**/*.jar", "libs-release-local/org/app*/*.jar"},
+}
+
+func TestGetGlobSearchPattern(t *testing.T) {
+	for _, sample := range getGlobSearchPatternProvider {
+		t.Run(sample.glob, func(t *testing.T) {
+			assert.Equal(t, sample.expected, getGlobSearchPattern(sample.glob))
+		})
+	}
+}
+
+// The ** segments may match no segments at all, so the search must also return the entries in the folder before them.
+func TestGlobSearchAql(t *testing.T) {
+	// The entries in the root of the repository are searched for.
+	aql, err := clientutils.CreateAqlBodyForSpecWithPattern(&clientutils.CommonParams{Pattern: getGlobSearchPattern("libs-release-local/**/*.jar"), Recursive: true})
+	assert.NoError(t, err)
+	assert.NotContains(t, aql, `"path":{"$ne":"."}`)
+	assert.Contains(t, aql, `"path":{"$match":"*"},"name":{"$match":"*.jar"}`)
+
+	// The entries directly in the org folder are searched for.
+	aql, err = clientutils.CreateAqlBodyForSpecWithPattern(&clientutils.CommonParams{Pattern: getGlobSearchPattern("libs-release-local/org/**/*.jar"), Recursive: true})
+	assert.NoError(t, err)
+	assert.Contains(t, aql, `"path":"org","name":{"$match":"*.jar"}`)
+	assert.Contains(t, aql, `"path":{"$match":"org/*"},"name":{"$match":"*.jar"}`)
+}
+
+func TestFilterGlobMatches(t *testing.T) {
+	reader := createTestTreeReader(t, getTestTreeResults())
+	defer reader.Close()
+	matches, err := filterGlobMatches(reader, globToRegexp("libs-release-local/org/*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []utils.SearchResult{
+		{Path: "libs-release-local/org/app.jar", Type: "file", Size: 10},
+		{Path: "libs-release-local/org/empty", Type: "folder"},
+		{Path: "libs-release-local/org/jfrog", Type: "folder"},
+	}, matches)
+}
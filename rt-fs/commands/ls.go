@@ -1,9 +1,16 @@
 package commands
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/buger/goterm"
 	"github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/generic"
@@ -17,29 +24,110 @@ import (
 // The minimal space between ls results in the screen
 const minSpace = 1
 
+// The format of the dates in the long listing format
+const longDateFormat = "2006-01-02 15:04"
+
+// The fields returned by the search for the long listing format. The modified_by field isn't returned by default.
+var longListingFields = []string{"type", "size", "created", "modified", "modified_by", "actual_sha1"}
+
 func GetLsCommand() components.Command {
 	return components.Command{
 		Name:        "ls",
 		Description: "Run ls.",
 		Aliases:     []string{"ls, list"},
 		Arguments:   getCommonArguments(),
-		Flags:       getCommonFlags(),
+		Flags:       getLsFlags(),
 		Action:      lsCmd,
 	}
 }
 
+func getLsFlags() []components.Flag {
+	return append(getCommonFlags(),
+		components.NewBoolFlag("l", "Use a long listing format, showing the type, size, created and modified dates, modified-by and SHA1 of each entry."),
+		components.NewBoolFlag("human-readable", "With -l, print sizes in human readable format, such as 1.5K, 234M or 2.0G. Unlike GNU ls, there is no -h shorthand, since -h prints the help."),
+		components.NewBoolFlag("R", "List the folders under the path recursively, grouping the entries by their folders."),
+		getDepthFlag(),
+		components.NewBoolFlag("t", "Sort by modified time, newest first."),
+		components.NewBoolFlag("S", "Sort by size, largest first."),
+		components.NewBoolFlag("r", "Reverse the order of the sort."),
+		components.NewBoolFlag("group-directories-first", "List the folders before the files."),
+		components.NewStringFlag("format", "Print one record per entry, with its full path, type, size, dates and checksums, in a machine-readable format instead of the columns layout. Possible values: json, csv and ndjson."),
+	)
+}
+
+type lsConfiguration struct {
+	*commonConfiguration
+	long          bool
+	humanReadable bool
+	recursive     bool
+	// The maximum number of levels listed below the path by a recursive listing. Unlimited if zero.
+	depth                 int
+	sortByTime            bool
+	sortBySize            bool
+	reverse               bool
+	groupDirectoriesFirst bool
+	// The machine-readable format of the output. The entries are printed in columns if empty.
+	format string
+}
+
 func lsCmd(c *components.Context) error {
-	conf, err := createCommonConfiguration(c)
+	commonConf, err := createCommonConfiguration(c)
 	if err != nil {
 		return err
 	}
+	conf := &lsConfiguration{
+		commonConfiguration: commonConf,
+		long:                c.GetBoolFlagValue("l"),
+		humanReadable:       c.GetBoolFlagValue("human-readable"),
+	}
+	if conf.humanReadable && !conf.long {
+		return errors.New("the human-readable flag can only be used together with the l flag")
+	}
+	conf.recursive = c.GetBoolFlagValue("R")
+	if conf.depth, err = getDepthFlagValue(c); err != nil {
+		return err
+	}
+	if conf.depth > 0 && !conf.recursive {
+		return errors.New("the depth flag can only be used together with the R flag")
+	}
+	conf.sortByTime = c.GetBoolFlagValue("t")
+	conf.sortBySize = c.GetBoolFlagValue("S")
+	if conf.sortByTime && conf.sortBySize {
+		return errors.New("the t and S flags cannot be used together")
+	}
+	conf.reverse = c.GetBoolFlagValue("r")
+	conf.groupDirectoriesFirst = c.GetBoolFlagValue("group-directories-first")
+	conf.format = c.GetStringFlagValue("format")
+	if err = checkFormat(conf.format); err != nil {
+		return err
+	}
+	if conf.format != "" && conf.long {
+		return errors.New("the format flag cannot be used together with the l flag")
+	}
 
 	return doLs(conf)
 }
 
-func doLs(c *commonConfiguration) error {
+func doLs(c *lsConfiguration) error {
+	if c.format != "" {
+		return doFormattedLs(c)
+	}
 	// Execute search command
-	reader, err := doSearch(c)
+	var include []string
+	if c.long {
+		include = longListingFields
+	}
+	if isGlob(c.path) {
+		return doGlobLs(c, include)
+	}
+	if c.recursive {
+		root, err := searchTree(c.commonConfiguration, c.depth, include)
+		if err != nil {
+			return err
+		}
+		return printRecursiveLsResults(os.Stdout, c, root)
+	}
+	reader, err := doSearch(c.commonConfiguration, include)
 	if err != nil {
 		return err
 	}
@@ -52,15 +140,179 @@ func doLs(c *commonConfiguration) error {
 	}
 
 	// Print results
-	printLsResults(searchResults, maxPathLength)
+	return printEntries(os.Stdout, c, searchResults, maxPathLength)
+}
 
+// Prints the entries of a single folder, in the columns layout or in the long listing format.
+// The entries are sorted by the sort flags.
+func printEntries(out io.Writer, c *lsConfiguration, searchResults []utils.SearchResult, maxPathLength int) error {
+	sort.SliceStable(searchResults, func(i, j int) bool {
+		return c.lessEntry(&searchResults[i], &searchResults[j])
+	})
+	if c.long {
+		return printLongLsResults(out, searchResults, c.humanReadable)
+	}
+	printLsResults(out, searchResults, maxPathLength)
 	return nil
 }
 
-func doSearch(c *commonConfiguration) (*content.ContentReader, error) {
+// Prints the entries of each folder in the tree, like GNU ls -R. Each folder starts with its full path,
+// followed by its entries, and the folders are separated by empty lines. The folders at the depth limit are
+// listed as entries of their parents, without their own entries.
+func printRecursiveLsResults(out io.Writer, c *lsConfiguration, root *treeNode) error {
+	// A file is listed by itself.
+	if root.Type != "folder" {
+		return printEntries(out, c, []utils.SearchResult{root.SearchResult}, len(root.Path))
+	}
+	first := true
+	var printFolder func(node *treeNode, folderPath string, level int) error
+	printFolder = func(node *treeNode, folderPath string, level int) error {
+		separator := "\n"
+		if first {
+			separator, first = "", false
+		}
+		if _, err := fmt.Fprintf(out, "%s%s:\n", separator, folderPath); err != nil {
+			return err
+		}
+		if entries, maxPathLength := node.getEntries(); len(entries) > 0 {
+			if err := printEntries(out, c, entries, maxPathLength); err != nil {
+				return err
+			}
+		}
+		if c.depth > 0 && level+1 >= c.depth {
+			return nil
+		}
+		// The folders are listed in the order of their entries.
+		for _, child := range c.sortedChildren(node) {
+			if child.Type == "folder" {
+				if err := printFolder(child, folderPath+"/"+child.Path, level+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return printFolder(root, root.Path, 0)
+}
+
+// Lists the entries matching the glob pattern, like GNU ls lists several arguments.
+func doGlobLs(c *lsConfiguration, include []string) error {
+	matches, err := searchGlob(c.commonConfiguration, true, include)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return errors.New("ls: cannot access '" + c.path + "': No such file or directory")
+	}
+	return printGlobLsResults(os.Stdout, c, matches, func(folderPath string) (*treeNode, error) {
+		return c.searchFolder(folderPath, include)
+	})
+}
+
+// Searches for the entries of the folder, and returns them as a tree whose root is the folder. The tree includes the
+// entries of the sub-folders if the listing is recursive. If the path leads to a file, the file is returned.
+func (c *lsConfiguration) searchFolder(folderPath string, include []string) (*treeNode, error) {
+	folderConf := &commonConfiguration{details: c.details, path: folderPath}
+	if c.recursive {
+		return searchTree(folderConf, c.depth, include)
+	}
+	reader, err := doSearch(folderConf, include)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return buildTree(reader, folderPath, 1)
+}
+
+// Prints the entries matching a glob pattern, like GNU ls prints several arguments. The matching files are listed
+// first by their full paths. Then the entries of each matching folder, returned by getFolder, are listed after the
+// path of the folder. The path of the folder is omitted if it's the only match, unless the listing is recursive.
+func printGlobLsResults(out io.Writer, c *lsConfiguration, matches []utils.SearchResult, getFolder func(folderPath string) (*treeNode, error)) error {
+	var files, folders []utils.SearchResult
+	maxPathLength := 0
+	for _, match := range matches {
+		if match.Type == "folder" {
+			folders = append(folders, match)
+			continue
+		}
+		files = append(files, match)
+		maxPathLength = max(maxPathLength, len(match.Path))
+	}
+	if len(files) > 0 {
+		if err := printEntries(out, c, files, maxPathLength); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return c.lessEntry(&folders[i], &folders[j])
+	})
+	for i, folder := range folders {
+		root, err := getFolder(folder.Path)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 || i > 0 {
+			if _, err = fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		if c.recursive {
+			if err = printRecursiveLsResults(out, c, root); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(matches) > 1 {
+			if _, err = fmt.Fprintf(out, "%s:\n", folder.Path); err != nil {
+				return err
+			}
+		}
+		if entries, maxPathLength := root.getEntries(); len(entries) > 0 {
+			if err = printEntries(out, c, entries, maxPathLength); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Returns true if entry a is listed before entry b. Like GNU ls, the entries are sorted by their names, unless sorted
+// by modified time or size. Entries with the same modified time or size are sorted by their names.
+func (c *lsConfiguration) lessEntry(a, b *utils.SearchResult) bool {
+	if c.groupDirectoriesFirst && (a.Type == "folder") != (b.Type == "folder") {
+		return a.Type == "folder"
+	}
+	compare := 0
+	if c.sortByTime {
+		compare = parseDate(b.Modified).Compare(parseDate(a.Modified))
+	} else if c.sortBySize {
+		compare = cmp.Compare(b.Size, a.Size)
+	}
+	if compare == 0 {
+		compare = strings.Compare(a.Path, b.Path)
+	}
+	if c.reverse {
+		return compare > 0
+	}
+	return compare < 0
+}
+
+// Returns the entries of the folder, sorted by the sort flags.
+func (c *lsConfiguration) sortedChildren(node *treeNode) []*treeNode {
+	children := append([]*treeNode{}, node.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		return c.lessEntry(&children[i].SearchResult, &children[j].SearchResult)
+	})
+	return children
+}
+
+// Searches for the entries of the path. The include fields are returned by the search, in addition to the default
+// fields, if not empty.
+func doSearch(c *commonConfiguration, include []string) (*content.ContentReader, error) {
 	// Run the first search
 	searchCmd := generic.NewSearchCommand()
-	searchSpec := spec.NewBuilder().Pattern(c.path).IncludeDirs(true).BuildSpec()
+	searchSpec := spec.NewBuilder().Pattern(c.path).IncludeDirs(true).Include(include).BuildSpec()
 	searchCmd.SetServerDetails(c.details).SetSpec(searchSpec)
 	if err := commands.Exec(searchCmd); err != nil {
 		return nil, err
@@ -85,13 +337,13 @@ func doSearch(c *commonConfiguration) (*content.ContentReader, error) {
 	}
 
 	// Run search again with "/" in the end of the pattern
-	searchSpec = spec.NewBuilder().Pattern(c.path + "/").IncludeDirs(true).BuildSpec()
+	searchSpec = spec.NewBuilder().Pattern(c.path + "/").IncludeDirs(true).Include(include).BuildSpec()
 	searchCmd.SetSpec(searchSpec)
 	err = commands.Exec(searchCmd)
 	return searchCmd.Result().Reader(), err
 }
 
-func printLsResults(searchResults []utils.SearchResult, maxPathLength int) {
+func printLsResults(out io.Writer, searchResults []utils.SearchResult, maxPathLength int) {
 	maxPathLength += minSpace
 	maxResultsInLine := goterm.Width() / maxPathLength
 	if maxResultsInLine == 0 {
@@ -102,7 +354,7 @@ func printLsResults(searchResults []utils.SearchResult, maxPathLength int) {
 	var color int
 	for i, res := range searchResults {
 		if i > 0 && i%maxResultsInLine == 0 {
-			fmt.Println()
+			fmt.Fprintln(out)
 		}
 		if res.Type == "folder" {
 			color = goterm.BLUE
@@ -110,9 +362,93 @@ func printLsResults(searchResults []utils.SearchResult, maxPathLength int) {
 			color = goterm.WHITE
 		}
 		output := fmt.Sprintf(pattern, res.Path)
-		fmt.Print(goterm.Color(output, color))
+		fmt.Fprint(out, goterm.Color(output, color))
+	}
+	fmt.Fprintln(out)
+}
+
+// Prints the search results in the long listing format, one entry per line, with aligned columns:
+// type, size, created, modified, modified-by, SHA1 and name.
+func printLongLsResults(out io.Writer, searchResults []utils.SearchResult, humanReadable bool) error {
+	rows := make([][]string, 0, len(searchResults))
+	widths := make([]int, 6)
+	for _, res := range searchResults {
+		row := []string{"-", formatSize(res.Size, humanReadable), formatDate(res.Created), formatDate(res.Modified), orDash(res.ModifiedBy), orDash(res.Sha1)}
+		if res.Type == "folder" {
+			row[0], row[1] = "d", "-"
+		}
+		for i, column := range row {
+			widths[i] = max(widths[i], len(column))
+		}
+		rows = append(rows, row)
+	}
+
+	for i, row := range rows {
+		var line strings.Builder
+		for j, column := range row {
+			// Align the sizes to the right, and the rest of the columns to the left.
+			if j == 1 {
+				fmt.Fprintf(&line, "%*s ", widths[j], column)
+			} else {
+				fmt.Fprintf(&line, "%-*s ", widths[j], column)
+			}
+		}
+		color := goterm.WHITE
+		if searchResults[i].Type == "folder" {
+			color = goterm.BLUE
+		}
+		if _, err := fmt.Fprintln(out, line.String()+goterm.Color(searchResults[i].Path, color)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Formats the size in bytes, or in the units of GNU ls -h if humanReadable is true, such as 1.5K, 234M or 2.0G.
+func formatSize(size int64, humanReadable bool) string {
+	if !humanReadable || size < 1024 {
+		return strconv.FormatInt(size, 10)
+	}
+	const units = "KMGTPE"
+	value := float64(size) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	// Like GNU ls, round up, and show a single decimal digit for values lower than 10.
+	if value < 10 {
+		if rounded := math.Ceil(value*10) / 10; rounded < 10 {
+			return strconv.FormatFloat(rounded, 'f', 1, 64) + units[unit:unit+1]
+		}
+	}
+	rounded := math.Ceil(value)
+	if rounded >= 1024 && unit < len(units)-1 {
+		return "1.0" + units[unit+1:unit+2]
+	}
+	return strconv.FormatFloat(rounded, 'f', 0, 64) + units[unit:unit+1]
+}
+
+// Formats a date returned by Artifactory, such as 2020-08-24T07:59:57.521Z, in the local time zone.
+func formatDate(date string) string {
+	parsed, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return orDash(date)
+	}
+	return parsed.Local().Format(longDateFormat)
+}
+
+// Parses a date returned by Artifactory. Returns the zero time if the date is missing or malformed.
+func parseDate(date string) time.Time {
+	parsed, _ := time.Parse(time.RFC3339, date)
+	return parsed
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
 	}
-	fmt.Println()
+	return value
 }
 
 // Gets the search results and builds an array of SearchResults.
@@ -1,12 +1,17 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/buger/goterm"
 	commandsutils "github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
 	"github.com/jfrog/jfrog-client-go/utils/io/content"
 	"github.com/stretchr/testify/assert"
 )
@@ -93,3 +98,141 @@ func prepareSample(t *testing.T, fileName string) string {
 	assert.NoError(t, err)
 	return dest.Name()
 }
+
+var formatSizeProvider = []struct {
+	size          int64
+	humanReadable bool
+	expected      string
+}{
+	{0, false, "0"},
+	{123456789, false, "123456789"},
+	{1023, true, "1023"},
+	{1024, true, "1.0K"},
+	{1536, true, "1.5K"},
+	{1537, true, "1.6K"},
+	{10 * 1024, true, "10K"},
+	{234*1024*1024 - 1, true, "234M"},
+	{1024*1024 - 1, true, "1.0M"},
+	{2 * 1024 * 1024 * 1024, true, "2.0G"},
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, sample := range formatSizeProvider {
+		t.Run(fmt.Sprintf("%v", sample), func(t *testing.T) {
+			assert.Equal(t, sample.expected, formatSize(sample.size, sample.humanReadable))
+		})
+	}
+}
+
+func TestPrintLongLsResults(t *testing.T) {
+	previousLocal := time.Local
+	time.Local = time.UTC
+	defer func() {
+		time.Local = previousLocal
+	}()
+
+	searchResults := []utils.SearchResult{
+		{Path: "org", Type: "folder", Created: "2020-08-19T05:40:04.075Z", Modified: "2020-08-19T05:40:04.075Z"},
+		{Path: "app.jar", Type: "file", Size: 1536, Created: "2020-08-24T07:59:57.521Z", Modified: "2020-08-24T10:59:57.363+03:00", ModifiedBy: "admin", Sha1: "55ca6286e3e4f4fba5d0448333fa99fc5a404a73"},
+	}
+	for _, humanReadable := range []bool{false, true} {
+		out := new(bytes.Buffer)
+		assert.NoError(t, printLongLsResults(out, searchResults, humanReadable))
+		size := "1536"
+		if humanReadable {
+			size = "1.5K"
+		}
+		expected := "d " + strings.Repeat(" ", len(size)-1) + "- 2020-08-19 05:40 2020-08-19 05:40 -     -                                        " + goterm.Color("org", goterm.BLUE) + "\n" +
+			"- " + size + " 2020-08-24 07:59 2020-08-24 07:59 admin 55ca6286e3e4f4fba5d0448333fa99fc5a404a73 " + goterm.Color("app.jar", goterm.WHITE) + "\n"
+		assert.Equal(t, expected, out.String())
+	}
+}
+
+func TestPrintGlobLsResults(t *testing.T) {
+	matches := []utils.SearchResult{
+		{Path: "libs-release-local/org/app.jar", Type: "file", Size: 10},
+		{Path: "libs-release-local/org/empty", Type: "folder"},
+		{Path: "libs-release-local/org/jfrog", Type: "folder"},
+	}
+	// Returns the entries of the folder, like the search of the folder.
+	getFolder := func(folderPath string) (*treeNode, error) {
+		var entries []utils.SearchResult
+		for _, result := range getTestTreeResults() {
+			if strings.HasPrefix(result.Path, folderPath+"/") {
+				entries = append(entries, result)
+			}
+		}
+		reader := createTestTreeReader(t, entries)
+		defer reader.Close()
+		return buildTree(reader, folderPath, 1)
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, printGlobLsResults(out, &lsConfiguration{}, matches, getFolder))
+	expected := goterm.Color("libs-release-local/org/app.jar ", goterm.WHITE) + "\n" +
+		"\nlibs-release-local/org/empty:\n" +
+		"\nlibs-release-local/org/jfrog:\n" +
+		goterm.Color("example ", goterm.BLUE) + "\n"
+	assert.Equal(t, expected, out.String())
+
+	// A single matching folder is listed without its path.
+	out.Reset()
+	assert.NoError(t, printGlobLsResults(out, &lsConfiguration{}, matches[2:], getFolder))
+	assert.Equal(t, goterm.Color("example ", goterm.BLUE)+"\n", out.String())
+}
+
+var sortEntriesProvider = []struct {
+	conf     lsConfiguration
+	expected []string
+}{
+	{lsConfiguration{}, []string{"a.jar", "b", "c.jar", "d", "e.jar"}},
+	{lsConfiguration{reverse: true}, []string{"e.jar", "d", "c.jar", "b", "a.jar"}},
+	{lsConfiguration{sortByTime: true}, []string{"d", "e.jar", "a.jar", "c.jar", "b"}},
+	{lsConfiguration{sortByTime: true, reverse: true}, []string{"b", "c.jar", "a.jar", "e.jar", "d"}},
+	{lsConfiguration{sortBySize: true}, []string{"c.jar", "a.jar", "e.jar", "b", "d"}},
+	{lsConfiguration{sortBySize: true, reverse: true}, []string{"d", "b", "e.jar", "a.jar", "c.jar"}},
+	{lsConfiguration{groupDirectoriesFirst: true}, []string{"b", "d", "a.jar", "c.jar", "e.jar"}},
+	{lsConfiguration{groupDirectoriesFirst: true, reverse: true}, []string{"d", "b", "e.jar", "c.jar", "a.jar"}},
+	{lsConfiguration{groupDirectoriesFirst: true, sortBySize: true}, []string{"b", "d", "c.jar", "a.jar", "e.jar"}},
+}
+
+func TestSortEntries(t *testing.T) {
+	for _, sample := range sortEntriesProvider {
+		t.Run(fmt.Sprintf("%+v", sample.conf), func(t *testing.T) {
+			// The entries with the same size are sorted by their names. The modified time of b is missing.
+			entries := []utils.SearchResult{
+				{Path: "e.jar", Type: "file", Size: 10, Modified: "2020-08-24T08:00:00.000Z"},
+				{Path: "c.jar", Type: "file", Size: 30, Modified: "2020-08-24T07:00:00.000+02:00"},
+				{Path: "a.jar", Type: "file", Size: 10, Modified: "2020-08-24T07:59:57.521Z"},
+				{Path: "d", Type: "folder", Modified: "2020-08-25T00:00:00.000Z"},
+				{Path: "b", Type: "folder"},
+			}
+			out := new(bytes.Buffer)
+			assert.NoError(t, printEntries(out, &sample.conf, entries, 5))
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Path)
+			}
+			assert.Equal(t, sample.expected, names)
+		})
+	}
+}
+
+func TestPrintRecursiveLsResultsSorted(t *testing.T) {
+	reader := createTestTreeReader(t, getTestTreeResults())
+	defer reader.Close()
+	root, err := buildTree(reader, "libs-release-local/org", 2)
+	assert.NoError(t, err)
+
+	// The folders are listed in the order of the entries.
+	out := new(bytes.Buffer)
+	assert.NoError(t, printRecursiveLsResults(out, &lsConfiguration{long: true, depth: 2, reverse: true}, root))
+	expected := "libs-release-local/org:\n" +
+		"d  - - - - - " + goterm.Color("jfrog", goterm.BLUE) + "\n" +
+		"d  - - - - - " + goterm.Color("empty", goterm.BLUE) + "\n" +
+		"- 10 - - - - " + goterm.Color("app.jar", goterm.WHITE) + "\n" +
+		"\nlibs-release-local/org/jfrog:\n" +
+		"d - - - - - " + goterm.Color("example", goterm.BLUE) + "\n" +
+		"\nlibs-release-local/org/empty:\n"
+	assert.Equal(t, expected, out.String())
+}
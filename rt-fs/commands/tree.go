@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/buger/goterm"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/commands/generic"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-cli-core/v2/common/commands"
+	"github.com/jfrog/jfrog-cli-core/v2/common/spec"
+	"github.com/jfrog/jfrog-cli-core/v2/plugins/components"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+)
+
+func GetTreeCommand() components.Command {
+	return components.Command{
+		Name:        "tree",
+		Description: "Run tree.",
+		Arguments:   getCommonArguments(),
+		Flags:       append(getCommonFlags(), getDepthFlag()),
+		Action:      treeCmd,
+	}
+}
+
+func getDepthFlag() components.Flag {
+	return components.NewStringFlag("depth", "Descend at most depth levels below the path. All the levels are shown if not set.")
+}
+
+func treeCmd(c *components.Context) error {
+	conf, err := createCommonConfiguration(c)
+	if err != nil {
+		return err
+	}
+	if isGlob(conf.path) {
+		return errors.New("wildcards are not supported by the tree command")
+	}
+	depth, err := getDepthFlagValue(c)
+	if err != nil {
+		return err
+	}
+
+	root, err := searchTree(conf, depth, nil)
+	if err != nil {
+		return err
+	}
+	return printTree(os.Stdout, root)
+}
+
+// Returns the value of the depth flag, or zero if the flag isn't set.
+func getDepthFlagValue(c *components.Context) (int, error) {
+	if !c.IsFlagSet("depth") {
+		return 0, nil
+	}
+	depth, err := c.GetIntFlagValue("depth")
+	if err != nil {
+		return 0, err
+	}
+	if depth < 1 {
+		return 0, errors.New("depth must be a positive number")
+	}
+	return depth, nil
+}
+
+// A folder or a file in a recursive listing. The Path of the SearchResult holds the name of the entry.
+type treeNode struct {
+	utils.SearchResult
+	children []*treeNode
+}
+
+// Searches for the entries under the path, and returns them as a tree whose root is the path.
+// Only the entries up to depth levels below the path are searched for, unless depth is zero.
+func searchTree(c *commonConfiguration, depth int, include []string) (*treeNode, error) {
+	if depth == 0 {
+		reader, err := doRecursiveSearch(c, include)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return buildTree(reader, c.path, 0)
+	}
+
+	reader, err := doSearch(c, include)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	root, err := buildTree(reader, c.path, 1)
+	if err != nil {
+		return nil, err
+	}
+	err = searchSubFolders(root, root.Path, depth-1, func(folderPath string) (*content.ContentReader, error) {
+		return listFolder(&commonConfiguration{details: c.details, path: folderPath}, include)
+	})
+	return root, err
+}
+
+// Adds the entries of the sub-folders of the folder to the tree, up to depth levels below them. Each sub-folder is
+// listed by a separate non-recursive search, so the entries below the depth limit aren't fetched from the server.
+func searchSubFolders(folder *treeNode, folderPath string, depth int, listFolder func(folderPath string) (*content.ContentReader, error)) error {
+	if depth == 0 {
+		return nil
+	}
+	for _, child := range folder.children {
+		if child.Type != "folder" {
+			continue
+		}
+		childPath := folderPath + "/" + child.Path
+		reader, err := listFolder(childPath)
+		if err != nil {
+			return err
+		}
+		subTree, err := buildTree(reader, childPath, 1)
+		if closeErr := reader.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		child.children = subTree.children
+		if err = searchSubFolders(child, childPath, depth-1, listFolder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Searches for the entries of the folder, without the entries of its sub-folders.
+func listFolder(c *commonConfiguration, include []string) (*content.ContentReader, error) {
+	searchCmd := generic.NewSearchCommand()
+	searchSpec := spec.NewBuilder().Pattern(strings.TrimSuffix(c.path, "/") + "/").IncludeDirs(true).Include(include).BuildSpec()
+	searchCmd.SetServerDetails(c.details).SetSpec(searchSpec)
+	if err := commands.Exec(searchCmd); err != nil {
+		return nil, err
+	}
+	return searchCmd.Result().Reader(), nil
+}
+
+// Searches for all the entries under the path recursively. If the path leads to a file, the file is returned.
+func doRecursiveSearch(c *commonConfiguration, include []string) (*content.ContentReader, error) {
+	searchCmd := generic.NewSearchCommand()
+	searchSpec := spec.NewBuilder().Pattern(strings.TrimSuffix(c.path, "/") + "/").IncludeDirs(true).Recursive(true).Include(include).BuildSpec()
+	searchCmd.SetServerDetails(c.details).SetSpec(searchSpec)
+	if err := commands.Exec(searchCmd); err != nil {
+		return nil, err
+	}
+
+	reader := searchCmd.Result().Reader()
+	if length, err := reader.Length(); err != nil || length > 0 {
+		return reader, err
+	}
+	// Nothing was found under the path, which may lead to a file.
+	if err := reader.Close(); err != nil {
+		return nil, err
+	}
+	return doSearch(c, include)
+}
+
+// Builds the tree of the search results under the path. Folders missing from the results are added by the paths of
+// their entries. The entries of each folder are sorted by their names.
+func buildTree(reader *content.ContentReader, rootPath string, depth int) (*treeNode, error) {
+	rootPath = strings.TrimSuffix(rootPath, "/")
+	root := &treeNode{SearchResult: utils.SearchResult{Path: rootPath, Type: "folder"}}
+	nodes := map[string]*treeNode{".": root}
+	var getNode func(relativePath string) *treeNode
+	getNode = func(relativePath string) *treeNode {
+		if node, exists := nodes[relativePath]; exists {
+			return node
+		}
+		parent := getNode(path.Dir(relativePath))
+		node := &treeNode{SearchResult: utils.SearchResult{Path: path.Base(relativePath), Type: "folder"}}
+		parent.children = append(parent.children, node)
+		nodes[relativePath] = node
+		return node
+	}
+
+	for result := new(utils.SearchResult); reader.NextRecord(result) == nil; result = new(utils.SearchResult) {
+		relativePath := strings.Trim(strings.TrimPrefix(result.Path, rootPath), "/")
+		if relativePath == "" {
+			relativePath = "."
+		} else if segments := strings.Split(relativePath, "/"); depth > 0 && len(segments) > depth {
+			// Only add the ancestor of the entry at the depth limit, in case it's missing from the results.
+			getNode(strings.Join(segments[:depth], "/"))
+			continue
+		}
+		node := getNode(relativePath)
+		name := node.Path
+		node.SearchResult = *result
+		node.Path = name
+	}
+	if err := reader.GetError(); err != nil {
+		return nil, err
+	}
+	sortTree(root)
+	return root, nil
+}
+
+func sortTree(node *treeNode) {
+	sort.Slice(node.children, func(i, j int) bool {
+		return node.children[i].Path < node.children[j].Path
+	})
+	for _, child := range node.children {
+		sortTree(child)
+	}
+}
+
+// Returns the results of the entries of the folder, holding their names in their Path, and the length of the longest name.
+func (node *treeNode) getEntries() (entries []utils.SearchResult, maxPathLength int) {
+	for _, child := range node.children {
+		entries = append(entries, child.SearchResult)
+		maxPathLength = max(maxPathLength, len(child.Path))
+	}
+	return
+}
+
+// Prints the tree as an indented tree with connectors, followed by the number of folders and files in it.
+func printTree(out io.Writer, root *treeNode) error {
+	if _, err := fmt.Fprintln(out, colorByType(root.Path, root.Type)); err != nil {
+		return err
+	}
+	folders, files := 0, 0
+	var printChildren func(node *treeNode, prefix string) error
+	printChildren = func(node *treeNode, prefix string) error {
+		for i, child := range node.children {
+			connector, childPrefix := "├── ", "│   "
+			if i == len(node.children)-1 {
+				connector, childPrefix = "└── ", "    "
+			}
+			if _, err := fmt.Fprintln(out, prefix+connector+colorByType(child.Path, child.Type)); err != nil {
+				return err
+			}
+			if child.Type != "folder" {
+				files++
+				continue
+			}
+			folders++
+			if err := printChildren(child, prefix+childPrefix); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := printChildren(root, ""); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "\n%s, %s\n", pluralize(folders, "directory", "directories"), pluralize(files, "file", "files"))
+	return err
+}
+
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %s", count, plural)
+}
+
+// Colors the name blue for folders, and white for files.
+func colorByType(name, itemType string) string {
+	if itemType == "folder" {
+		return goterm.Color(name, goterm.BLUE)
+	}
+	return goterm.Color(name, goterm.WHITE)
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/buger/goterm"
+	"github.com/jfrog/jfrog-cli-core/v2/artifactory/utils"
+	"github.com/jfrog/jfrog-client-go/utils/io/content"
+	"github.com/stretchr/testify/assert"
+)
+
+// The results of a recursive search under libs-release-local/org, in the order Artifactory may return them.
+// The org/jfrog/example folder is missing from the results, and is added by the paths of its entries.
+func getTestTreeResults() []utils.SearchResult {
+	return []utils.SearchResult{
+		{Path: "libs-release-local/org/jfrog", Type: "folder"},
+		{Path: "libs-release-local/org/jfrog/example/gradle/api-1.0.jar", Type: "file", Size: 1536},
+		{Path: "libs-release-local/org/app.jar", Type: "file", Size: 10},
+		{Path: "libs-release-local/org/jfrog/example/gradle", Type: "folder"},
+		{Path: "libs-release-local/org/jfrog/example/gradle/api-1.0.pom", Type: "file", Size: 20},
+		{Path: "libs-release-local/org/empty", Type: "folder"},
+	}
+}
+
+func createTestTreeReader(t *testing.T, results []utils.SearchResult) *content.ContentReader {
+	writer, err := content.NewContentWriter("results", true, false)
+	assert.NoError(t, err)
+	for _, result := range results {
+		writer.Write(result)
+	}
+	assert.NoError(t, writer.Close())
+	return content.NewContentReader(writer.GetFilePath(), "results")
+}
+
+// Returns the tree as nested names, for comparing trees in tests.
+func getTreeNames(node *treeNode) string {
+	if len(node.children) == 0 {
+		return node.Path
+	}
+	children := ""
+	for i, child := range node.children {
+		if i > 0 {
+			children += " "
+		}
+		children += getTreeNames(child)
+	}
+	return fmt.Sprintf("%s[%s]", node.Path, children)
+}
+
+var buildTreeProvider = []struct {
+	rootPath string
+	depth    int
+	expected string
+}{
+	{"libs-release-local/org", 0, "libs-release-local/org[app.jar empty jfrog[example[gradle[api-1.0.jar api-1.0.pom]]]]"},
+	{"libs-release-local/org/", 0, "libs-release-local/org[app.jar empty jfrog[example[gradle[api-1.0.jar api-1.0.pom]]]]"},
+	{"libs-release-local/org", 1, "libs-release-local/org[app.jar empty jfrog]"},
+	{"libs-release-local/org", 2, "libs-release-local/org[app.jar empty jfrog[example]]"},
+	{"libs-release-local/org", 3, "libs-release-local/org[app.jar empty jfrog[example[gradle]]]"},
+}
+
+func TestBuildTree(t *testing.T) {
+	for _, sample := range buildTreeProvider {
+		t.Run(fmt.Sprintf("%v", sample), func(t *testing.T) {
+			reader := createTestTreeReader(t, getTestTreeResults())
+			defer reader.Close()
+			root, err := buildTree(reader, sample.rootPath, sample.depth)
+			assert.NoError(t, err)
+			assert.Equal(t, sample.expected, getTreeNames(root))
+		})
+	}
+}
+
+var searchSubFoldersProvider = []struct {
+	depth    int
+	expected string
+	listed   []string
+}{
+	{1, "libs-release-local/org[app.jar empty jfrog]", nil},
+	{2, "libs-release-local/org[app.jar empty jfrog[example]]", []string{"libs-release-local/org/empty", "libs-release-local/org/jfrog"}},
+	{3, "libs-release-local/org[app.jar empty jfrog[example[gradle]]]", []string{"libs-release-local/org/empty", "libs-release-local/org/jfrog", "libs-release-local/org/jfrog/example"}},
+}
+
+func TestSearchSubFolders(t *testing.T) {
+	results := append(getTestTreeResults(), utils.SearchResult{Path: "libs-release-local/org/jfrog/example", Type: "folder"})
+	// Returns the direct entries of the folder, like a non-recursive search of the folder.
+	getEntries := func(folderPath string) *content.ContentReader {
+		var entries []utils.SearchResult
+		for _, result := range results {
+			if path.Dir(result.Path) == folderPath {
+				entries = append(entries, result)
+			}
+		}
+		return createTestTreeReader(t, entries)
+	}
+
+	for _, sample := range searchSubFoldersProvider {
+		t.Run(fmt.Sprintf("%v", sample), func(t *testing.T) {
+			reader := getEntries("libs-release-local/org")
+			defer reader.Close()
+			root, err := buildTree(reader, "libs-release-local/org", 1)
+			assert.NoError(t, err)
+
+			var listed []string
+			err = searchSubFolders(root, root.Path, sample.depth-1, func(folderPath string) (*content.ContentReader, error) {
+				listed = append(listed, folderPath)
+				return getEntries(folderPath), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, sample.expected, getTreeNames(root))
+			// The folders at the depth limit aren't listed.
+			assert.Equal(t, sample.listed, listed)
+		})
+	}
+}
+
+func TestBuildTreeOfFile(t *testing.T) {
+	reader := createTestTreeReader(t, []utils.SearchResult{{Path: "libs-release-local/org/app.jar", Type: "file", Size: 10}})
+	defer reader.Close()
+	root, err := buildTree(reader, "libs-release-local/org/app.jar", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &treeNode{SearchResult: utils.SearchResult{Path: "libs-release-local/org/app.jar", Type: "file", Size: 10}}, root)
+}
+
+func TestPrintTree(t *testing.T) {
+	reader := createTestTreeReader(t, getTestTreeResults())
+	defer reader.Close()
+	root, err := buildTree(reader, "libs-release-local/org", 0)
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, printTree(out, root))
+	folder := func(name string) string {
+		return goterm.Color(name, goterm.BLUE)
+	}
+	file := func(name string) string {
+		return goterm.Color(name, goterm.WHITE)
+	}
+	expected := folder("libs-release-local/org") + "\n" +
+		"├── " + file("app.jar") + "\n" +
+		"├── " + folder("empty") + "\n" +
+		"└── " + folder("jfrog") + "\n" +
+		"    └── " + folder("example") + "\n" +
+		"        └── " + folder("gradle") + "\n" +
+		"            ├── " + file("api-1.0.jar") + "\n" +
+		"            └── " + file("api-1.0.pom") + "\n" +
+		"\n4 directories, 3 files\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestPrintRecursiveLsResults(t *testing.T) {
+	previousLocal := time.Local
+	time.Local = time.UTC
+	defer func() {
+		time.Local = previousLocal
+	}()
+
+	reader := createTestTreeReader(t, getTestTreeResults())
+	defer reader.Close()
+	root, err := buildTree(reader, "libs-release-local/org", 2)
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, printRecursiveLsResults(out, &lsConfiguration{long: true, depth: 2}, root))
+	expected := "libs-release-local/org:\n" +
+		"- 10 - - - - " + goterm.Color("app.jar", goterm.WHITE) + "\n" +
+		"d  - - - - - " + goterm.Color("empty", goterm.BLUE) + "\n" +
+		"d  - - - - - " + goterm.Color("jfrog", goterm.BLUE) + "\n" +
+		"\nlibs-release-local/org/empty:\n" +
+		"\nlibs-release-local/org/jfrog:\n" +
+		"d - - - - - " + goterm.Color("example", goterm.BLUE) + "\n"
+	assert.Equal(t, expected, out.String())
+}
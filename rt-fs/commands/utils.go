@@ -60,9 +60,6 @@ func checkInputs(c *components.Context) error {
 	if len(c.Arguments) != 1 {
 		return errors.New("wrong number of arguments. Expected: 1, " + "Received: " + strconv.Itoa(len(c.Arguments)))
 	}
-	if strings.Contains(c.Arguments[0], "*") {
-		return errors.New("wildcards are not supported in paths")
-	}
 	return nil
 }
 
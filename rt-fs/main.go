@@ -23,5 +23,6 @@ func getCommands() []components.Command {
 	return []components.Command{
 		commands.GetLsCommand(),
 		commands.GetCatCommand(),
+		commands.GetTreeCommand(),
 	}
 }